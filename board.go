@@ -229,15 +229,74 @@ func (b *Board) drawForBlack(darkMode bool) string {
 	return s
 }
 
+// UnicodeBoardOptions configures Position.UnicodeBoard.
+type UnicodeBoardOptions struct {
+	// LastMove, when non-nil, highlights the move's origin and
+	// destination squares with parentheses.
+	LastMove *Move
+	// Perspective selects the side the board is drawn from.
+	Perspective Color
+	// DarkMode renders pieces with their DarkString glyphs for dark
+	// terminals.
+	DarkMode bool
+}
+
+// UnicodeBoard returns a visual representation of the position like
+// Board.Draw2, optionally marking the last move's origin and
+// destination squares with parentheses.  Cells are three characters
+// wide to leave room for the markers.
+func (pos *Position) UnicodeBoard(opts UnicodeBoardOptions) string {
+	s := "\n"
+	if opts.Perspective == Black {
+		s += "  H  G  F  E  D  C  B  A\n"
+	} else {
+		s += "  A  B  C  D  E  F  G  H\n"
+	}
+	for i := range numOfSquaresInRow {
+		r := 7 - i
+		if opts.Perspective == Black {
+			r = i
+		}
+		s += Rank(r).String()
+		for j := range numOfSquaresInRow {
+			f := j
+			if opts.Perspective == Black {
+				f = numOfSquaresInRow - 1 - j
+			}
+			sq := NewSquare(File(f), Rank(r))
+			cell := "-"
+			if p := pos.board.Piece(sq); p != NoPiece {
+				if opts.DarkMode {
+					cell = p.DarkString()
+				} else {
+					cell = p.String()
+				}
+			}
+			if opts.LastMove != nil && (sq == opts.LastMove.s1 || sq == opts.LastMove.s2) {
+				s += "(" + cell + ")"
+			} else {
+				s += " " + cell + " "
+			}
+		}
+		s += "\n"
+	}
+	return s
+}
+
 // String implements the fmt.Stringer interface and returns
 // a string in the FEN board format: rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR.
 func (b *Board) String() string {
+	// A board field is at most 71 bytes (8 ranks of 8 plus 7 separators)
+	return string(b.appendFEN(make([]byte, 0, 71)))
+}
+
+// appendFEN appends the FEN board field to buf and returns the extended
+// buffer, avoiding intermediate string allocations for callers that build
+// a full FEN.
+func (b *Board) appendFEN(buf []byte) []byte {
 	const maxRankValue = 7
 	const numOfFiles = 8
 
-	// Use a buffer to build the string
-	buf := make([]byte, 0, 71)
-
 	// Buffer to count empty squares
 	emptyCount := 0
 
@@ -275,8 +334,7 @@ func (b *Board) String() string {
 		}
 	}
 
-	// Convert to string once at the end
-	return string(buf)
+	return buf
 }
 
 // Piece returns the piece for the given square.
@@ -291,6 +349,73 @@ func (b *Board) Piece(sq Square) Piece {
 	return NoPiece
 }
 
+// Bitboard returns the raw bitboard for the given piece.  Squares are
+// mapped to bits from the most significant bit down: A1 is bit 63, B1 is
+// bit 62, and so on through H8 at bit 0 (i.e. square sq occupies bit
+// 63-sq).  Returns 0 for NoPiece.
+func (b *Board) Bitboard(p Piece) uint64 {
+	if p == NoPiece {
+		return 0
+	}
+	return uint64(b.bbForPiece(p))
+}
+
+// Occupancy returns the raw bitboard of all squares occupied by pieces of
+// the given color, using the same bit-to-square mapping as Bitboard.
+// Returns the occupancy of both colors for NoColor.
+func (b *Board) Occupancy(c Color) uint64 {
+	switch c {
+	case White:
+		return uint64(b.whiteSqs)
+	case Black:
+		return uint64(b.blackSqs)
+	default:
+		return uint64(b.whiteSqs | b.blackSqs)
+	}
+}
+
+// MailboxOffBoard is the sentinel value marking off-board cells in the
+// 10x12 mailbox returned by Mailbox10x12.
+const MailboxOffBoard int8 = 127
+
+// Mailbox0x88 returns the board in the 0x88 layout used by many classic
+// engines: a 128-element array indexed by rank*16+file, where indexes
+// with the 0x88 bit set are off the board.  Off-board cells hold
+// NoPiece; callers detect them with index&0x88 != 0.
+func (b *Board) Mailbox0x88() [128]Piece {
+	var mailbox [128]Piece
+	for sq := range numOfSquaresInBoard {
+		square := Square(sq)
+		mailbox[int(square.Rank())*16+int(square.File())] = b.Piece(square)
+	}
+	return mailbox
+}
+
+// Mailbox10x12 returns the board in the 10x12 mailbox layout: a
+// 120-element array of two guard ranks above and below the board and
+// one guard file on each side, indexed by 21 + rank*10 + file for
+// on-board squares.  Off-board cells hold MailboxOffBoard; on-board
+// cells hold 0 for empty squares, the piece's PieceType (King 1 through
+// Pawn 6) for White, and its negation for Black.
+func (b *Board) Mailbox10x12() [120]int8 {
+	var mailbox [120]int8
+	for i := range mailbox {
+		mailbox[i] = MailboxOffBoard
+	}
+	for sq := range numOfSquaresInBoard {
+		square := Square(sq)
+		value := int8(0)
+		if p := b.Piece(square); p != NoPiece {
+			value = int8(p.Type())
+			if p.Color() == Black {
+				value = -value
+			}
+		}
+		mailbox[21+int(square.Rank())*10+int(square.File())] = value
+	}
+	return mailbox
+}
+
 // MarshalText implements the encoding.TextMarshaler interface and returns
 // a string in the FEN board format: rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR.
 func (b *Board) MarshalText() ([]byte, error) {
@@ -1,6 +1,7 @@
 package chess
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -88,3 +89,105 @@ func TestBoardTranspose(t *testing.T) {
 		t.Fatalf("expected board string %s but got %s", b, board.String())
 	}
 }
+
+func TestBoardBitboardAccessors(t *testing.T) {
+	b := StartingPosition().Board()
+
+	// A1 maps to bit 63, so the white rooks on a1/h1 occupy bits 63 and 56.
+	rooks := b.Bitboard(WhiteRook)
+	expectedRooks := uint64(1)<<63 | uint64(1)<<56
+	if rooks != expectedRooks {
+		t.Fatalf("expected white rook bitboard %x but got %x", expectedRooks, rooks)
+	}
+	if b.Bitboard(NoPiece) != 0 {
+		t.Fatal("expected empty bitboard for NoPiece")
+	}
+
+	var white, black uint64
+	for _, p := range allPieces {
+		if p.Color() == White {
+			white |= b.Bitboard(p)
+		} else {
+			black |= b.Bitboard(p)
+		}
+	}
+	if b.Occupancy(White) != white {
+		t.Fatalf("expected white occupancy %x but got %x", white, b.Occupancy(White))
+	}
+	if b.Occupancy(Black) != black {
+		t.Fatalf("expected black occupancy %x but got %x", black, b.Occupancy(Black))
+	}
+	if b.Occupancy(NoColor) != white|black {
+		t.Fatalf("expected full occupancy %x but got %x", white|black, b.Occupancy(NoColor))
+	}
+}
+
+func TestPositionUnicodeBoard(t *testing.T) {
+	g := NewGame()
+	if err := g.PushMove("e4", nil); err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	move := g.Moves()[0]
+	pos := g.Position()
+
+	s := pos.UnicodeBoard(UnicodeBoardOptions{LastMove: move})
+	if got := strings.Count(s, "("); got != 2 {
+		t.Fatalf("expected 2 highlighted squares but got %d in %s", got, s)
+	}
+	// The vacated origin and the pawn's destination are both marked.
+	if !strings.Contains(s, "(-)") {
+		t.Fatalf("expected highlighted empty origin square in %s", s)
+	}
+	pawn := pos.Board().Piece(E4)
+	if !strings.Contains(s, "("+pawn.String()+")") {
+		t.Fatalf("expected highlighted pawn on e4 in %s", s)
+	}
+
+	// Without a last move, nothing is marked.
+	if s := pos.UnicodeBoard(UnicodeBoardOptions{}); strings.Contains(s, "(") {
+		t.Fatalf("expected no highlights in %s", s)
+	}
+
+	// Dark mode flips the piece glyphs.
+	dark := pos.UnicodeBoard(UnicodeBoardOptions{LastMove: move, DarkMode: true})
+	if !strings.Contains(dark, "("+pawn.DarkString()+")") {
+		t.Fatalf("expected dark mode pawn glyph in %s", dark)
+	}
+
+	// Black's perspective lists the files in reverse.
+	if s := pos.UnicodeBoard(UnicodeBoardOptions{Perspective: Black}); !strings.Contains(s, "H  G  F") {
+		t.Fatalf("expected black perspective header in %s", s)
+	}
+}
+
+func TestBoardMailbox(t *testing.T) {
+	board := unsafeFEN("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1").Board()
+
+	m88 := board.Mailbox0x88()
+	// a1 = 0x00, e1 = 0x04, h8 = 0x77 in the 0x88 layout.
+	if m88[0x00] != WhiteRook || m88[0x04] != WhiteKing || m88[0x77] != BlackRook {
+		t.Fatalf("unexpected 0x88 pieces: %s %s %s", m88[0x00], m88[0x04], m88[0x77])
+	}
+	if m88[0x14] != WhitePawn || m88[0x34] != NoPiece {
+		t.Fatalf("unexpected 0x88 pieces: %s %s", m88[0x14], m88[0x34])
+	}
+	// Indexes with the 0x88 bit set are off the board.
+	if m88[0x08] != NoPiece {
+		t.Fatalf("expected NoPiece off board but got %s", m88[0x08])
+	}
+
+	m120 := board.Mailbox10x12()
+	// a1 = 21, e1 = 25, e8 = 95 in the 10x12 layout.
+	if m120[21] != int8(Rook) || m120[25] != int8(King) || m120[95] != -int8(King) {
+		t.Fatalf("unexpected 10x12 pieces: %d %d %d", m120[21], m120[25], m120[95])
+	}
+	// Empty e4, guard cells around the board.
+	if m120[55] != 0 {
+		t.Fatalf("expected empty e4 but got %d", m120[55])
+	}
+	for _, idx := range []int{0, 19, 20, 29, 119} {
+		if m120[idx] != MailboxOffBoard {
+			t.Fatalf("expected off-board sentinel at %d but got %d", idx, m120[idx])
+		}
+	}
+}
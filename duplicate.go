@@ -0,0 +1,70 @@
+package chess
+
+import (
+	"sort"
+	"strings"
+)
+
+// DuplicateFinder clusters games that contain the same mainline moves so
+// large collections can be deduplicated.  Games are grouped by the set of
+// moves played together with the final position, so two games that reach
+// the same position with the same moves in a different order (a
+// transposition) land in the same group.  Halfmove clocks and en passant
+// squares are ignored when comparing final positions since they depend on
+// move order.
+type DuplicateFinder struct {
+	groups map[string][]*Game
+	order  []string
+}
+
+// NewDuplicateFinder returns an empty DuplicateFinder.
+func NewDuplicateFinder() *DuplicateFinder {
+	return &DuplicateFinder{
+		groups: make(map[string][]*Game),
+	}
+}
+
+// Add records a game for duplicate detection.
+func (f *DuplicateFinder) Add(g *Game) {
+	key := duplicateKey(g)
+	if _, ok := f.groups[key]; !ok {
+		f.order = append(f.order, key)
+	}
+	f.groups[key] = append(f.groups[key], g)
+}
+
+// Groups returns the clusters of duplicate games found so far, in the
+// order their first member was added.  Games without a duplicate are not
+// reported.
+func (f *DuplicateFinder) Groups() [][]*Game {
+	ret := make([][]*Game, 0, len(f.order))
+	for _, key := range f.order {
+		if len(f.groups[key]) < 2 {
+			continue
+		}
+		ret = append(ret, f.groups[key])
+	}
+	return ret
+}
+
+// duplicateKey derives the grouping key for a game: its mainline move set
+// in sorted order plus the piece placement, turn, and castling rights of
+// the final position.
+func duplicateKey(g *Game) string {
+	moves := g.Moves()
+	moveStrs := make([]string, 0, len(moves))
+	for _, m := range moves {
+		moveStrs = append(moveStrs, m.String())
+	}
+	sort.Strings(moveStrs)
+
+	finalPos := g.GetRootMove().position
+	if len(moves) > 0 {
+		finalPos = moves[len(moves)-1].position
+	}
+	// Piece placement, turn, and castling rights only; clocks and the en
+	// passant square depend on the order the moves were played in.
+	fields := strings.SplitN(finalPos.String(), " ", 4)
+
+	return strings.Join(fields[:3], " ") + "|" + strings.Join(moveStrs, " ")
+}
@@ -0,0 +1,75 @@
+package chess
+
+import "testing"
+
+func gameFromSANs(t *testing.T, sans ...string) *Game {
+	t.Helper()
+	g := NewGame()
+	for _, san := range sans {
+		if err := g.PushMove(san, nil); err != nil {
+			t.Fatal("recieved unexpected error", err)
+		}
+	}
+	return g
+}
+
+func TestDuplicateFinder(t *testing.T) {
+	exact1 := gameFromSANs(t, "e4", "e5", "Nf3", "Nc6")
+	exact2 := gameFromSANs(t, "e4", "e5", "Nf3", "Nc6")
+	// Same moves and final position reached in a different order.
+	transposed := gameFromSANs(t, "Nf3", "Nc6", "e4", "e5")
+	// Same final position but via different moves.
+	other := gameFromSANs(t, "d4", "d5", "c4")
+
+	finder := NewDuplicateFinder()
+	for _, g := range []*Game{exact1, other, exact2, transposed} {
+		finder.Add(g)
+	}
+
+	groups := finder.Groups()
+	if len(groups) != 1 {
+		t.Fatalf("expected 1 duplicate group but got %d", len(groups))
+	}
+	if len(groups[0]) != 3 {
+		t.Fatalf("expected 3 games in group but got %d", len(groups[0]))
+	}
+	for _, g := range []*Game{exact1, exact2, transposed} {
+		found := false
+		for _, member := range groups[0] {
+			if member == g {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Fatal("expected game to be in the duplicate group")
+		}
+	}
+}
+
+func TestDuplicateFinderTranspositionOrders(t *testing.T) {
+	// Every ordering of the same move set reaching the Queen's Gambit
+	// Declined position lands in one group.
+	g1 := gameFromSANs(t, "d4", "d5", "c4", "e6")
+	g2 := gameFromSANs(t, "c4", "e6", "d4", "d5")
+	g3 := gameFromSANs(t, "d4", "e6", "c4", "d5")
+
+	finder := NewDuplicateFinder()
+	finder.Add(g1)
+	finder.Add(g2)
+	finder.Add(g3)
+
+	groups := finder.Groups()
+	if len(groups) != 1 {
+		t.Fatalf("expected 1 duplicate group but got %d", len(groups))
+	}
+	if len(groups[0]) != 3 {
+		t.Fatalf("expected 3 games in group but got %d", len(groups[0]))
+	}
+
+	// A longer game containing the same moves plus more is not grouped.
+	finder.Add(gameFromSANs(t, "d4", "d5", "c4", "e6", "Nc3"))
+	if len(finder.Groups()) != 1 || len(finder.Groups()[0]) != 3 {
+		t.Fatal("expected the longer game to stay ungrouped")
+	}
+}
@@ -21,7 +21,10 @@ Example usage:
 */
 package chess
 
-import "sync"
+import (
+	"math/bits"
+	"sync"
+)
 
 // engine implements chess move generation and position analysis.
 type engine struct{}
@@ -37,7 +40,7 @@ type engine struct{}
 // Each move is validated to ensure it doesn't leave the king in check
 func (engine) CalcMoves(pos *Position, first bool) []Move {
 	// generate possible moves
-	moves := standardMoves(pos, first)
+	moves := standardMoves(pos, first, false)
 	// return moves including castles
 	return append(moves, castleMoves(pos)...)
 }
@@ -52,12 +55,7 @@ func (engine) CalcMoves(pos *Position, first bool) []Move {
 // If the position has cached valid moves in pos.validMoves, those will be
 // used. Otherwise, moves will be calculated to determine the status.
 func (engine) Status(pos *Position) Method {
-	var hasMove bool
-	if pos.validMoves != nil {
-		hasMove = len(pos.validMoves) > 0
-	} else {
-		hasMove = len(engine{}.CalcMoves(pos, true)) > 0
-	}
+	hasMove := pos.HasLegalMove()
 	if !pos.inCheck && !hasMove {
 		return Stalemate
 	} else if pos.inCheck && !hasMove {
@@ -85,11 +83,12 @@ var movePool = sync.Pool{
 
 // standardMoves generates all standard (non-castling) legal moves for the
 // current position. If first is true, returns after finding the first
-// legal move.
+// legal move. If pseudo is true, moves that leave the moving side's king
+// in check are kept instead of filtered out.
 //
 // The function uses a sync.Pool of move arrays to reduce allocations. Each
 // move is validated to ensure it doesn't leave the king in check.
-func standardMoves(pos *Position, first bool) []Move {
+func standardMoves(pos *Position, first, pseudo bool) []Move {
 	moves, _ := movePool.Get().(*[maxPossibleMoves]Move)
 	defer movePool.Put(moves)
 	count := 0
@@ -132,7 +131,7 @@ func standardMoves(pos *Position, first bool) []Move {
 					for _, pt := range promoPieceTypes {
 						m.promo = pt
 						addTags(&m, pos)
-						if !m.HasTag(inCheck) {
+						if pseudo || !m.HasTag(inCheck) {
 							// Copy the valid move to the array
 							moves[count] = m
 							count++
@@ -147,7 +146,7 @@ func standardMoves(pos *Position, first bool) []Move {
 				} else {
 					m.promo = 0
 					addTags(&m, pos)
-					if !m.HasTag(inCheck) {
+					if pseudo || !m.HasTag(inCheck) {
 						moves[count] = m
 						count++
 						if first {
@@ -172,6 +171,7 @@ func standardMoves(pos *Position, first bool) []Move {
 //   - Capture: The move captures an opponent's piece
 //   - EnPassant: The move is an en passant capture
 //   - Check: The move puts the opponent in check
+//   - Promotion: The move promotes a pawn
 //   - inCheck: The move leaves the moving side's king in check (illegal)
 //   - KingSideCastle: The move is a king-side castle
 //   - QueenSideCastle: The move is a queen-side castle
@@ -182,6 +182,9 @@ func addTags(m *Move, pos *Position) {
 	} else if m.s2 == pos.enPassantSquare && p.Type() == Pawn {
 		m.AddTag(EnPassant)
 	}
+	if m.promo != NoPieceType {
+		m.AddTag(Promotion)
+	}
 	// determine if move is castle
 	if (p == WhiteKing && m.s1 == E1) || (p == BlackKing && m.s1 == E8) {
 		switch m.s2 {
@@ -201,9 +204,33 @@ func addTags(m *Move, pos *Position) {
 	}
 	// determine if opponent in check after move
 	cp.turn = cp.turn.Other()
+	m.checkType = NoCheck
 	if isInCheck(cp) {
 		m.AddTag(Check)
+		m.checkType = classifyCheck(m, cp)
+	}
+}
+
+// classifyCheck determines whether the checked king is attacked by the
+// moved piece, a piece the move uncovered, or both.  pos is the position
+// after the move with the checked side on move.
+func classifyCheck(m *Move, pos *Position) CheckType {
+	checkers := checkingPieces(pos)
+	if bits.OnesCount64(uint64(checkers)) > 1 {
+		return DoubleCheck
+	}
+	moved := bbForSquare(m.s2)
+	// On a castle the rook delivers the check from its own square.
+	switch {
+	case m.HasTag(KingSideCastle):
+		moved |= bbForSquare(F1) | bbForSquare(F8)
+	case m.HasTag(QueenSideCastle):
+		moved |= bbForSquare(D1) | bbForSquare(D8)
+	}
+	if checkers&moved != 0 {
+		return NormalCheck
 	}
+	return DiscoveredCheck
 }
 
 // isInCheck returns true if the side to move is in check in the given position.
@@ -219,6 +246,31 @@ func isInCheck(pos *Position) bool {
 	return squaresAreAttacked(pos, kingSq)
 }
 
+// checkingPieces returns a bitboard of the opponent pieces currently
+// giving check to the side to move's king.
+func checkingPieces(pos *Position) bitboard {
+	kingSq := pos.board.whiteKingSq
+	if pos.Turn() == Black {
+		kingSq = pos.board.blackKingSq
+	}
+	if kingSq == NoSquare {
+		return 0
+	}
+	otherColor := pos.Turn().Other()
+	occ := ^pos.board.emptySqs
+	dia := diaAttack(occ, kingSq)
+	hv := hvAttack(occ, kingSq)
+	bb := (dia | hv) & pos.board.bbForPiece(NewPiece(Queen, otherColor))
+	bb |= hv & pos.board.bbForPiece(NewPiece(Rook, otherColor))
+	bb |= dia & pos.board.bbForPiece(NewPiece(Bishop, otherColor))
+	bb |= bbKnightMoves[kingSq] & pos.board.bbForPiece(NewPiece(Knight, otherColor))
+	// The squares an enemy pawn attacks the king from mirror the squares
+	// a friendly pawn on the king's square would attack.
+	bb |= bitboard(PawnAttacks(pos.Turn(), kingSq)) & pos.board.bbForPiece(NewPiece(Pawn, otherColor))
+	bb |= bbKingMoves[kingSq] & pos.board.bbForPiece(NewPiece(King, otherColor))
+	return bb
+}
+
 // squaresAreAttacked returns true if any of the given squares are attacked
 // by the opponent in the given position.
 //
@@ -320,6 +372,67 @@ func bbForPossibleMoves(pos *Position, pt PieceType, sq Square) bitboard {
 	return bitboard(0)
 }
 
+// PieceAttacks returns the attack bitboard for a piece of the given type
+// on the given square.  The occupancy mask blocks sliding pieces and is
+// ignored for kings and knights.  It is a pure movement primitive,
+// independent of any position: no legality, check, or side-to-move rules
+// apply, which makes it useful for tutorials, puzzle generation, and
+// attack analysis.  Bitboards use the same mapping as Board.Bitboard: A1
+// is bit 63 and H8 is bit 0.  Pawns attack differently per color; use
+// PawnAttacks for them (Pawn here returns 0).
+func PieceAttacks(pt PieceType, sq Square, occupancy uint64) uint64 {
+	if sq < A1 || sq > H8 {
+		return 0
+	}
+	occ := bitboard(occupancy)
+	switch pt {
+	case King:
+		return uint64(bbKingMoves[sq])
+	case Queen:
+		return uint64(diaAttack(occ, sq) | hvAttack(occ, sq))
+	case Rook:
+		return uint64(hvAttack(occ, sq))
+	case Bishop:
+		return uint64(diaAttack(occ, sq))
+	case Knight:
+		return uint64(bbKnightMoves[sq])
+	}
+	return 0
+}
+
+// PawnAttacks returns the squares a pawn of the given color on the given
+// square attacks: the diagonal capture squares, ignoring whether anything
+// is there to capture.  The bit mapping matches Board.Bitboard.
+func PawnAttacks(c Color, sq Square) uint64 {
+	if sq < A1 || sq > H8 {
+		return 0
+	}
+	bb := bbForSquare(sq)
+	if c == White {
+		return uint64(((bb & ^bbFileH) >> 9) | ((bb & ^bbFileA) >> 7))
+	}
+	return uint64(((bb & ^bbFileH) << 7) | ((bb & ^bbFileA) << 9))
+}
+
+// ThreatMap returns every square attacked by the given color, aggregated
+// across all of its pieces.  Unlike ValidMoves it is pure attack
+// coverage: legality and the side to move are ignored, king-adjacent
+// squares count, and pawn threats are the diagonal capture squares
+// whether or not anything stands on them.  The squares are returned in
+// ascending square order (A1 to H8).
+func (pos *Position) ThreatMap(by Color) []Square {
+	occ := uint64(^pos.board.emptySqs)
+	var threats bitboard
+	for _, pt := range []PieceType{King, Queen, Rook, Bishop, Knight} {
+		pieceBB := pos.board.bbForPiece(NewPiece(pt, by))
+		for _, sq := range squaresOfBitboard(pieceBB) {
+			threats |= bitboard(PieceAttacks(pt, sq, occ))
+		}
+	}
+	threats |= pawnAttacksBB(pos.board.pawnBB(by), by)
+	return squaresOfBitboard(threats)
+}
+
 // castleMoves returns all legal castling moves for the current position.
 //
 // A castling move is legal if:
@@ -0,0 +1,258 @@
+package chess
+
+// This file implements a deliberately simple legal-move generator used as
+// an independent reference for testing the optimized bitboard generator
+// in engine.go.  Pseudo-legal moves are produced square by square from
+// piece movement rules, applied to a copy of the board, and rejected if
+// the moving side's king is attacked afterwards.
+
+// ValidMovesReference returns the position's legal moves using the slow
+// reference generator.  The returned moves carry only origin,
+// destination, and promotion; callers should not rely on tags.  Use
+// ValidMoves instead outside of tests.
+func (pos *Position) ValidMovesReference() []Move {
+	var moves []Move
+	for sq := range numOfSquaresInBoard {
+		p := pos.board.Piece(Square(sq))
+		if p == NoPiece || p.Color() != pos.turn {
+			continue
+		}
+		for _, s2 := range refPseudoTargets(pos, p, Square(sq)) {
+			if p.Type() == Pawn && (s2.Rank() == Rank8 || s2.Rank() == Rank1) {
+				for _, pt := range promoPieceTypes {
+					m := Move{s1: Square(sq), s2: s2, promo: pt}
+					if refMoveIsLegal(pos, &m) {
+						moves = append(moves, m)
+					}
+				}
+			} else {
+				m := Move{s1: Square(sq), s2: s2}
+				if refMoveIsLegal(pos, &m) {
+					moves = append(moves, m)
+				}
+			}
+		}
+	}
+	return append(moves, refCastleMoves(pos)...)
+}
+
+// refPseudoTargets returns the squares the piece at sq could move to by
+// its movement rules alone, ignoring king safety.
+func refPseudoTargets(pos *Position, p Piece, sq Square) []Square {
+	var targets []Square
+	f, r := int(sq.File()), int(sq.Rank())
+	add := func(df, dr int) (Square, bool) {
+		nf, nr := f+df, r+dr
+		if nf < 0 || nf > 7 || nr < 0 || nr > 7 {
+			return NoSquare, false
+		}
+		return NewSquare(File(nf), Rank(nr)), true
+	}
+
+	switch p.Type() {
+	case Pawn:
+		dir, startRank := 1, 1
+		if p.Color() == Black {
+			dir, startRank = -1, 6
+		}
+		if to, ok := add(0, dir); ok && pos.board.Piece(to) == NoPiece {
+			targets = append(targets, to)
+			if r == startRank {
+				if to2, ok2 := add(0, 2*dir); ok2 && pos.board.Piece(to2) == NoPiece {
+					targets = append(targets, to2)
+				}
+			}
+		}
+		for _, df := range []int{-1, 1} {
+			to, ok := add(df, dir)
+			if !ok {
+				continue
+			}
+			captured := pos.board.Piece(to)
+			if (captured != NoPiece && captured.Color() != p.Color()) || to == pos.enPassantSquare {
+				targets = append(targets, to)
+			}
+		}
+	case Knight, King:
+		for _, d := range refStepDeltas(p.Type()) {
+			if to, ok := add(d[0], d[1]); ok {
+				if captured := pos.board.Piece(to); captured == NoPiece || captured.Color() != p.Color() {
+					targets = append(targets, to)
+				}
+			}
+		}
+	case Bishop, Rook, Queen:
+		for _, d := range refSlideDeltas(p.Type()) {
+			for step := 1; ; step++ {
+				to, ok := add(d[0]*step, d[1]*step)
+				if !ok {
+					break
+				}
+				captured := pos.board.Piece(to)
+				if captured == NoPiece {
+					targets = append(targets, to)
+					continue
+				}
+				if captured.Color() != p.Color() {
+					targets = append(targets, to)
+				}
+				break
+			}
+		}
+	}
+	return targets
+}
+
+// refStepDeltas returns the single-step offsets for knights and kings.
+func refStepDeltas(pt PieceType) [][2]int {
+	if pt == Knight {
+		return [][2]int{{1, 2}, {2, 1}, {2, -1}, {1, -2}, {-1, -2}, {-2, -1}, {-2, 1}, {-1, 2}}
+	}
+	return [][2]int{{1, 0}, {1, 1}, {0, 1}, {-1, 1}, {-1, 0}, {-1, -1}, {0, -1}, {1, -1}}
+}
+
+// refSlideDeltas returns the ray directions for sliding pieces.
+func refSlideDeltas(pt PieceType) [][2]int {
+	diagonal := [][2]int{{1, 1}, {1, -1}, {-1, 1}, {-1, -1}}
+	straight := [][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}}
+	switch pt {
+	case Bishop:
+		return diagonal
+	case Rook:
+		return straight
+	default:
+		return append(diagonal, straight...)
+	}
+}
+
+// refApply returns a new board with the move naively applied, including
+// en passant capture and promotion.
+func refApply(pos *Position, m *Move) *Board {
+	mp := pos.board.SquareMap()
+	p := mp[m.s1]
+	delete(mp, m.s1)
+	if p.Type() == Pawn && m.s2 == pos.enPassantSquare && m.s1.File() != m.s2.File() {
+		// The captured pawn sits beside the origin square, behind the
+		// en passant target.
+		delete(mp, NewSquare(m.s2.File(), m.s1.Rank()))
+	}
+	if m.promo != NoPieceType {
+		p = NewPiece(m.promo, p.Color())
+	}
+	mp[m.s2] = p
+	return NewBoard(mp)
+}
+
+// refMoveIsLegal applies the move and reports whether the moving side's
+// king is safe afterwards.
+func refMoveIsLegal(pos *Position, m *Move) bool {
+	b := refApply(pos, m)
+	kingSq := NoSquare
+	king := NewPiece(King, pos.turn)
+	for sq := range numOfSquaresInBoard {
+		if b.Piece(Square(sq)) == king {
+			kingSq = Square(sq)
+			break
+		}
+	}
+	// king should only be missing in tests / examples
+	if kingSq == NoSquare {
+		return true
+	}
+	return !refSquareAttacked(b, kingSq, pos.turn.Other())
+}
+
+// refSquareAttacked reports whether the given square is attacked by any
+// piece of the given color, derived from movement rules alone.
+func refSquareAttacked(b *Board, sq Square, by Color) bool {
+	f, r := int(sq.File()), int(sq.Rank())
+	at := func(df, dr int) Piece {
+		nf, nr := f+df, r+dr
+		if nf < 0 || nf > 7 || nr < 0 || nr > 7 {
+			return NoPiece
+		}
+		return b.Piece(NewSquare(File(nf), Rank(nr)))
+	}
+
+	// pawn attacks come from the rank in front of the pawn
+	dir := -1
+	if by == Black {
+		dir = 1
+	}
+	for _, df := range []int{-1, 1} {
+		if p := at(df, dir); p == NewPiece(Pawn, by) {
+			return true
+		}
+	}
+	for _, d := range refStepDeltas(Knight) {
+		if p := at(d[0], d[1]); p == NewPiece(Knight, by) {
+			return true
+		}
+	}
+	for _, d := range refStepDeltas(King) {
+		if p := at(d[0], d[1]); p == NewPiece(King, by) {
+			return true
+		}
+	}
+	for _, d := range refSlideDeltas(Queen) {
+		for step := 1; ; step++ {
+			p := at(d[0]*step, d[1]*step)
+			nf, nr := f+d[0]*step, r+d[1]*step
+			if nf < 0 || nf > 7 || nr < 0 || nr > 7 {
+				break
+			}
+			if p == NoPiece {
+				continue
+			}
+			if p.Color() == by {
+				diagonal := d[0] != 0 && d[1] != 0
+				if p.Type() == Queen ||
+					(diagonal && p.Type() == Bishop) ||
+					(!diagonal && p.Type() == Rook) {
+					return true
+				}
+			}
+			break
+		}
+	}
+	return false
+}
+
+// refCastleMoves mirrors the castling legality rules of castleMoves
+// using the reference attack detection.
+func refCastleMoves(pos *Position) []Move {
+	var moves []Move
+	b := pos.board
+	if pos.turn == White {
+		if pos.castleRights.CanCastle(White, KingSide) &&
+			b.Piece(F1) == NoPiece && b.Piece(G1) == NoPiece &&
+			!refSquareAttacked(b, E1, Black) &&
+			!refSquareAttacked(b, F1, Black) &&
+			!refSquareAttacked(b, G1, Black) {
+			moves = append(moves, Move{s1: E1, s2: G1, tags: KingSideCastle})
+		}
+		if pos.castleRights.CanCastle(White, QueenSide) &&
+			b.Piece(B1) == NoPiece && b.Piece(C1) == NoPiece && b.Piece(D1) == NoPiece &&
+			!refSquareAttacked(b, E1, Black) &&
+			!refSquareAttacked(b, D1, Black) &&
+			!refSquareAttacked(b, C1, Black) {
+			moves = append(moves, Move{s1: E1, s2: C1, tags: QueenSideCastle})
+		}
+		return moves
+	}
+	if pos.castleRights.CanCastle(Black, KingSide) &&
+		b.Piece(F8) == NoPiece && b.Piece(G8) == NoPiece &&
+		!refSquareAttacked(b, E8, White) &&
+		!refSquareAttacked(b, F8, White) &&
+		!refSquareAttacked(b, G8, White) {
+		moves = append(moves, Move{s1: E8, s2: G8, tags: KingSideCastle})
+	}
+	if pos.castleRights.CanCastle(Black, QueenSide) &&
+		b.Piece(B8) == NoPiece && b.Piece(C8) == NoPiece && b.Piece(D8) == NoPiece &&
+		!refSquareAttacked(b, E8, White) &&
+		!refSquareAttacked(b, D8, White) &&
+		!refSquareAttacked(b, C8, White) {
+		moves = append(moves, Move{s1: E8, s2: C8, tags: QueenSideCastle})
+	}
+	return moves
+}
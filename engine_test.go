@@ -44,7 +44,7 @@ func BenchmarkStandardMoves(b *testing.B) {
 			b.ReportAllocs()
 
 			for i := 0; i < b.N; i++ {
-				moves := standardMoves(bm.pos, bm.wantFirst)
+				moves := standardMoves(bm.pos, bm.wantFirst, false)
 				// Prevent compiler optimization
 				if len(moves) == 0 {
 					b.Fatal("unexpected zero moves")
@@ -61,7 +61,7 @@ func BenchmarkStandardMoves_PawnPromotions(b *testing.B) {
 	b.ReportAllocs()
 
 	for i := 0; i < b.N; i++ {
-		moves := standardMoves(pos, false)
+		moves := standardMoves(pos, false, false)
 		if len(moves) == 0 {
 			b.Fatal("unexpected zero moves")
 		}
@@ -85,7 +85,7 @@ func BenchmarkStandardMoves_BoardDensity(b *testing.B) {
 		b.Run(p.name, func(b *testing.B) {
 			b.ReportAllocs()
 			for i := 0; i < b.N; i++ {
-				moves := standardMoves(pos, false)
+				moves := standardMoves(pos, false, false)
 				if len(moves) == 0 && p.name != "Empty" {
 					b.Fatal("unexpected zero moves")
 				}
@@ -183,3 +183,127 @@ func mustPosition(fen string) *Position {
 	}
 	return pos
 }
+
+func TestValidMovesReference(t *testing.T) {
+	fens := append([]string{}, validFENs...)
+	fens = append(fens,
+		"r1bqk2r/pppp1ppp/2n2n2/2b1p3/2B1P3/2N2N2/PPPP1PPP/R1BQK2R w KQkq - 0 1",
+		"4k3/PPPP4/8/8/8/8/4pppp/4K3 w - - 0 1",
+		"r3kb1r/p2nqppp/5n2/1B2p1B1/4P3/1Q6/PPP2PPP/R3K2R b KQkq - 1 12",
+		"r4b1r/ppp3pp/8/4p3/2Pq4/3P4/PP2QPPP/2k1K2R w K - 0 18",
+		"rn2k1r1/ppqb4/4p1n1/3pPp1Q/8/P1PP4/4NPPP/R1BK1B1R b q - 0 14",
+		"r3k2r/pbppqpb1/1pn3p1/7p/1N2pPn1/1PP4N/PB1P2PP/2QRKR2 b kq f3 0 1",
+		"r3k1r1/ppq5/2n1p1n1/3p1pBQ/b2P3P/P1P5/4NPP1/R3KB1R b q - 0 18",
+		"8/7p/3k2p1/8/2p2P2/R5bP/6K1/4r3 w - - 0 44",
+	)
+	for _, fen := range fens {
+		pos := mustPosition(fen)
+		want := map[string]bool{}
+		for _, m := range pos.ValidMoves() {
+			want[m.String()] = true
+		}
+		got := map[string]bool{}
+		for _, m := range pos.ValidMovesReference() {
+			got[m.String()] = true
+		}
+		for k := range want {
+			if !got[k] {
+				t.Errorf("fen %s: reference generator missing move %s", fen, k)
+			}
+		}
+		for k := range got {
+			if !want[k] {
+				t.Errorf("fen %s: reference generator has extra move %s", fen, k)
+			}
+		}
+	}
+}
+
+func bbFromSquares(squares ...Square) uint64 {
+	var bb uint64
+	for _, sq := range squares {
+		bb |= uint64(bbForSquare(sq))
+	}
+	return bb
+}
+
+func TestPieceAttacks(t *testing.T) {
+	// A knight on e4 on an empty board reaches eight squares.
+	expected := bbFromSquares(D6, F6, C5, G5, C3, G3, D2, F2)
+	if got := PieceAttacks(Knight, E4, 0); got != expected {
+		t.Fatalf("expected knight attacks %x but got %x", expected, got)
+	}
+
+	// A rook on a1 is blocked by a piece on a4 (inclusive of the blocker).
+	occ := bbFromSquares(A4)
+	expected = bbFromSquares(A2, A3, A4, B1, C1, D1, E1, F1, G1, H1)
+	if got := PieceAttacks(Rook, A1, occ); got != expected {
+		t.Fatalf("expected rook attacks %x but got %x", expected, got)
+	}
+
+	// A queen combines rook and bishop attacks.
+	if got := PieceAttacks(Queen, D4, occ); got != PieceAttacks(Rook, D4, occ)|PieceAttacks(Bishop, D4, occ) {
+		t.Fatal("expected queen attacks to combine rook and bishop attacks")
+	}
+
+	// A king on h8 has three neighbors.
+	expected = bbFromSquares(G8, G7, H7)
+	if got := PieceAttacks(King, H8, 0); got != expected {
+		t.Fatalf("expected king attacks %x but got %x", expected, got)
+	}
+
+	if PieceAttacks(Pawn, E4, 0) != 0 {
+		t.Fatal("expected no pawn attacks from PieceAttacks")
+	}
+	if PieceAttacks(Knight, NoSquare, 0) != 0 {
+		t.Fatal("expected no attacks for invalid square")
+	}
+}
+
+func TestPawnAttacks(t *testing.T) {
+	if got := PawnAttacks(White, E4); got != bbFromSquares(D5, F5) {
+		t.Fatalf("expected white pawn attacks d5 f5 but got %x", got)
+	}
+	if got := PawnAttacks(Black, E4); got != bbFromSquares(D3, F3) {
+		t.Fatalf("expected black pawn attacks d3 f3 but got %x", got)
+	}
+	// Edge pawns attack a single square.
+	if got := PawnAttacks(White, A2); got != bbFromSquares(B3) {
+		t.Fatalf("expected white pawn attacks b3 but got %x", got)
+	}
+	if got := PawnAttacks(Black, H7); got != bbFromSquares(G6) {
+		t.Fatalf("expected black pawn attacks g6 but got %x", got)
+	}
+}
+
+func TestThreatMap(t *testing.T) {
+	// A lone white rook on a1 and king on e1 against a black king on e8.
+	pos := unsafeFEN("4k3/8/8/8/8/8/8/R3K3 w - - 0 1")
+	threats := pos.ThreatMap(White)
+	want := map[Square]bool{
+		// Rook along the rank; the defended king square counts too.
+		B1: true, C1: true, D1: true, E1: true,
+		A2: true, A3: true, A4: true, A5: true, A6: true, A7: true, A8: true,
+		D2: true, E2: true, F2: true, F1: true, // king coverage
+	}
+	if len(threats) != len(want) {
+		t.Fatalf("expected %d threatened squares but got %d: %v", len(want), len(threats), threats)
+	}
+	for _, sq := range threats {
+		if !want[sq] {
+			t.Fatalf("unexpected threatened square %s", sq)
+		}
+	}
+
+	// Pawn threats are the capture squares regardless of occupancy, and
+	// the map covers the side not on move too.
+	pos = unsafeFEN("4k3/8/8/3p4/8/8/8/4K3 w - - 0 1")
+	black := pos.ThreatMap(Black)
+	found := map[Square]bool{}
+	for _, sq := range black {
+		found[sq] = true
+	}
+	if !found[C4] || !found[E4] {
+		t.Fatalf("expected pawn threats on c4 and e4 in %v", black)
+	}
+}
@@ -3,6 +3,7 @@ package chess
 import (
 	"errors"
 	"fmt"
+	"strings"
 )
 
 // PGNError custom error types for different PGN errors.
@@ -39,6 +40,20 @@ var (
 	ErrNoGameFound = errors.New("no game found in PGN data")
 )
 
+// AmbiguousMoveError is returned by AlgebraicNotation.Decode when an
+// under-specified move matches more than one legal move, such as "Nd7"
+// in a position where two knights can reach d7.  Candidates holds the
+// fully disambiguated notation of every matching move.
+type AmbiguousMoveError struct {
+	Notation   string
+	Candidates []string
+}
+
+func (e *AmbiguousMoveError) Error() string {
+	return fmt.Sprintf("chess: move %s is ambiguous: %s",
+		e.Notation, strings.Join(e.Candidates, ", "))
+}
+
 type ParserError struct {
 	Message    string
 	TokenValue string
@@ -53,6 +53,34 @@ func decodeFEN(fen string) (*Position, error) {
 	}, nil
 }
 
+// decodeFENLenient decodes FEN notation like decodeFEN but also accepts
+// the 4-field form emitted by EPD records and many web sources, which
+// omits the halfmove clock and fullmove number.  Missing counters default
+// to 0 and 1 respectively.
+func decodeFENLenient(fen string) (*Position, error) {
+	const lenientFENParts = 4
+	fen = strings.TrimSpace(fen)
+	if len(strings.Fields(fen)) == lenientFENParts {
+		fen += " 0 1"
+	}
+	return decodeFEN(fen)
+}
+
+// DecodeEPD decodes an Extended Position Description record into a
+// Position.  EPD records contain the first four FEN fields (board, turn,
+// castling rights, en passant square) optionally followed by opcodes,
+// which are ignored.  The halfmove clock and fullmove number default to
+// 0 and 1 respectively.  An error is returned if the position fields
+// cannot be parsed.
+func DecodeEPD(epd string) (*Position, error) {
+	const epdParts = 4
+	fields := strings.Fields(epd)
+	if len(fields) < epdParts {
+		return nil, errors.New("chess: epd invalid format")
+	}
+	return decodeFENLenient(strings.Join(fields[:epdParts], " "))
+}
+
 // preallocated array to avoid strings.Split allocation
 //
 //nolint:gochecknoglobals // this is a preallocated array.
@@ -191,15 +219,16 @@ func fenFormRank(rankStr string, m map[File]Piece) error {
 
 func formCastleRights(castleStr string) (CastleRights, error) {
 	// check for duplicates aka. KKkq right now is valid
-	for _, s := range []string{"K", "Q", "k", "q", "-"} {
-		if strings.Count(castleStr, s) > 1 {
+	for i, r := range castleStr {
+		if strings.ContainsRune(castleStr[i+1:], r) {
 			return "-", fmt.Errorf("chess: fen invalid castle rights %s", castleStr)
 		}
 	}
 	for _, r := range castleStr {
-		c := fmt.Sprintf("%c", r)
-		switch c {
-		case "K", "Q", "k", "q", "-":
+		switch {
+		case strings.ContainsRune("KQkq-", r):
+		case r >= 'A' && r <= 'H', r >= 'a' && r <= 'h':
+			// Shredder-FEN rights name the castling rook's file (Chess960).
 		default:
 			return "-", fmt.Errorf("chess: fen invalid castle rights %s", castleStr)
 		}
@@ -207,6 +236,35 @@ func formCastleRights(castleStr string) (CastleRights, error) {
 	return CastleRights(castleStr), nil
 }
 
+// NormalizeFEN parses the given FEN and returns it in a canonical form:
+// castling rights are ordered KQkq and the en passant square is emitted
+// only when an en passant capture is actually possible (the X-FEN rule).
+// An error is returned if the FEN is invalid.  Normalizing makes FENs
+// from different sources comparable as plain strings.
+func NormalizeFEN(fen string) (string, error) {
+	pos, err := decodeFEN(fen)
+	if err != nil {
+		return "", err
+	}
+	pos.castleRights = normalizeCastleRights(pos.castleRights)
+	return pos.XFENString(), nil
+}
+
+// normalizeCastleRights reorders castle rights into the canonical KQkq
+// order.
+func normalizeCastleRights(rights CastleRights) CastleRights {
+	var sb strings.Builder
+	for _, r := range "KQkq" {
+		if strings.ContainsRune(string(rights), r) {
+			sb.WriteRune(r)
+		}
+	}
+	if sb.Len() == 0 {
+		return "-"
+	}
+	return CastleRights(sb.String())
+}
+
 func formEnPassant(enPassant string) (Square, error) {
 	if enPassant == "-" {
 		return NoSquare, nil
@@ -133,3 +133,109 @@ func BenchmarkFenBoard(b *testing.B) {
 		})
 	}
 }
+
+func TestNormalizeFEN(t *testing.T) {
+	tests := []struct {
+		fen      string
+		expected string
+	}{
+		// Scrambled castle rights are reordered to KQkq.
+		{
+			"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w qkKQ - 0 1",
+			"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+		},
+		// The en passant square is dropped when no capture is possible.
+		{
+			"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1",
+			"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq - 0 1",
+		},
+		// The en passant square is kept when a capture is possible.
+		{
+			"rnbqkbnr/pp1ppppp/8/8/1Pp1PP2/8/P1PP2PP/RNBQKBNR b qkQK b3 0 3",
+			"rnbqkbnr/pp1ppppp/8/8/1Pp1PP2/8/P1PP2PP/RNBQKBNR b KQkq b3 0 3",
+		},
+		// Already canonical FENs pass through unchanged.
+		{
+			"8/8/8/4k3/8/8/8/R3K2R w KQ - 0 1",
+			"8/8/8/4k3/8/8/8/R3K2R w KQ - 0 1",
+		},
+	}
+	for _, tt := range tests {
+		got, err := NormalizeFEN(tt.fen)
+		if err != nil {
+			t.Fatal("recieved unexpected error", err)
+		}
+		if got != tt.expected {
+			t.Fatalf("expected %s but got %s", tt.expected, got)
+		}
+	}
+	for _, fen := range invalidFENs {
+		if _, err := NormalizeFEN(fen); err == nil {
+			t.Fatalf("expected error for invalid fen %s", fen)
+		}
+	}
+}
+
+func TestDecodeFENLenient(t *testing.T) {
+	// A 4-field FEN (no clocks) decodes with defaulted counters.
+	pos, err := decodeFENLenient("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq -")
+	if err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	expected := "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+	if pos.String() != expected {
+		t.Fatalf("expected fen %s but got %s", expected, pos.String())
+	}
+
+	// A full 6-field FEN still decodes unchanged.
+	full := "rnbqkbnr/pp1ppppp/8/2p5/4P3/8/PPPP1PPP/RNBQKBNR w KQkq c6 1 2"
+	pos, err = decodeFENLenient(full)
+	if err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	if pos.String() != full {
+		t.Fatalf("expected fen %s but got %s", full, pos.String())
+	}
+
+	// Other field counts remain invalid.
+	if _, err := decodeFENLenient("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq"); err == nil {
+		t.Fatal("expected error for 3 field fen")
+	}
+
+	// The lenient form is available as a game option.
+	fn, err := FENLenient("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq -")
+	if err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	g := NewGame(fn)
+	if g.FEN() != expected {
+		t.Fatalf("expected fen %s but got %s", expected, g.FEN())
+	}
+}
+
+func TestDecodeEPD(t *testing.T) {
+	// A bare 4-field EPD decodes with defaulted counters.
+	pos, err := DecodeEPD("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq -")
+	if err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	expected := "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+	if pos.String() != expected {
+		t.Fatalf("expected fen %s but got %s", expected, pos.String())
+	}
+
+	// Trailing opcodes are ignored.
+	pos, err = DecodeEPD(`rnbqkbnr/pp1ppppp/8/2p5/4P3/8/PPPP1PPP/RNBQKBNR w KQkq c6 bm Nf3; id "B20";`)
+	if err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	expected = "rnbqkbnr/pp1ppppp/8/2p5/4P3/8/PPPP1PPP/RNBQKBNR w KQkq c6 0 1"
+	if pos.String() != expected {
+		t.Fatalf("expected fen %s but got %s", expected, pos.String())
+	}
+
+	// Records missing position fields remain invalid.
+	if _, err := DecodeEPD("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq"); err == nil {
+		t.Fatal("expected error for 3 field epd")
+	}
+}
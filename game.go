@@ -23,10 +23,14 @@ package chess
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/csv"
 	"errors"
 	"fmt"
 	"io"
 	"slices"
+	"strconv"
 	"strings"
 )
 
@@ -34,8 +38,11 @@ import (
 type Outcome string
 
 const (
+	// UnknownOutcome indicates that the game's result was never
+	// recorded, e.g. movetext without a result terminator.
 	UnknownOutcome Outcome = ""
-	// NoOutcome indicates that a game is in progress or ended without a result.
+	// NoOutcome indicates that a game is in progress, marked by an
+	// explicit "*" terminator.
 	NoOutcome Outcome = "*"
 	// WhiteWon indicates that white won the game.
 	WhiteWon Outcome = "1-0"
@@ -50,6 +57,22 @@ func (o Outcome) String() string {
 	return string(o)
 }
 
+// Ongoing returns true if the outcome does not record a finished game:
+// either the game is in progress (NoOutcome) or its result was never
+// recorded (UnknownOutcome).
+func (o Outcome) Ongoing() bool {
+	return o == NoOutcome || o == UnknownOutcome
+}
+
+// pgnString returns the outcome's movetext terminator.  UnknownOutcome
+// serializes as "*" since PGN has no way to express a missing result.
+func (o Outcome) pgnString() string {
+	if o == UnknownOutcome {
+		return NoOutcome.String()
+	}
+	return o.String()
+}
+
 // A Method is the method that generated the outcome.
 type Method uint8
 
@@ -79,6 +102,26 @@ const (
 	// InsufficientMaterial indicates that the game was automatically drawn
 	// because there was insufficient material for checkmate.
 	InsufficientMaterial
+	// ThirdCheck indicates that a three-check game was won by
+	// delivering the third check.
+	ThirdCheck
+	// KingInCenter indicates that a king-of-the-hill game was won by
+	// moving the king to one of the four central squares.
+	KingInCenter
+)
+
+// A Variant selects terminal conditions beyond standard chess.  Only
+// the win conditions differ; movement rules are unchanged.
+type Variant uint8
+
+const (
+	// VariantStandard is regular chess with no extra terminal conditions.
+	VariantStandard Variant = iota
+	// VariantThreeCheck ends the game when a side delivers its third check.
+	VariantThreeCheck
+	// VariantKingOfTheHill ends the game when a king reaches one of the
+	// four central squares (d4, e4, d5, e5).
+	VariantKingOfTheHill
 )
 
 // TagPairs represents a collection of PGN tag pairs.
@@ -86,14 +129,21 @@ type TagPairs map[string]string
 
 // A Game represents a single chess game.
 type Game struct {
-	pos                  *Position  // Current position
-	outcome              Outcome    // Game result
-	tagPairs             TagPairs   // PGN tag pairs
-	rootMove             *Move      // Root of move tree
-	currentMove          *Move      // Current position in tree
-	comments             [][]string // Game comments
-	method               Method     // How the game ended
-	ignoreAutomaticDraws bool       // Flag for automatic draw handling
+	pos                  *Position               // Current position
+	outcome              Outcome                 // Game result
+	tagPairs             TagPairs                // PGN tag pairs
+	rootMove             *Move                   // Root of move tree
+	currentMove          *Move                   // Current position in tree
+	comments             [][]string              // Game comments
+	raw                  string                  // Original PGN text, if preserved by the scanner
+	onMoveHooks          []func(*Move)           // Callbacks invoked after each move
+	onGameEndHooks       []func(Outcome, Method) // Callbacks invoked when the game ends
+	method               Method                  // How the game ended
+	drawOffers           map[Color]int           // Ply at which each color may offer a draw again
+	drawOfferCooldown    int                     // Opponent moves required between offers (default 1)
+	repetitionCounts     map[uint64]int          // Lazily built mainline position-hash counts
+	ignoreAutomaticDraws bool                    // Flag for automatic draw handling
+	variant              Variant                 // Extra terminal conditions, if any
 }
 
 // PGN takes a reader and returns a function that updates
@@ -151,6 +201,25 @@ func FEN(fen string) (func(*Game), error) {
 	}, nil
 }
 
+// FENLenient is like FEN but also accepts the 4-field form without the
+// halfmove clock and fullmove number, as emitted by EPD records and many
+// web sources.  Missing counters default to 0 and 1 respectively.
+func FENLenient(fen string) (func(*Game), error) {
+	pos, err := decodeFENLenient(fen)
+	if err != nil {
+		return nil, err
+	}
+	if pos == nil {
+		return nil, errors.New("chess: invalid FEN")
+	}
+	return func(g *Game) {
+		pos.inCheck = isInCheck(pos)
+		g.pos = pos
+		g.rootMove.position = pos
+		g.evaluatePositionStatus()
+	}, nil
+}
+
 // NewGame returns a new game in the standard starting position.
 // Optional functions can be provided to configure the initial game state.
 //
@@ -183,11 +252,64 @@ func NewGame(options ...func(*Game)) *Game {
 	return game
 }
 
+// SetPosition resets the game in place to continue from the given
+// position, e.g. after setting up a board in an editor.  The existing
+// move tree and any recorded draw offers are discarded; a copy of pos
+// becomes the new root position.  The "SetUp" and "FEN" tag pairs are
+// updated so the exported PGN reproduces the position, and the outcome
+// and method are refreshed via evaluatePositionStatus.  An error is
+// returned if pos is nil.
+func (g *Game) SetPosition(pos *Position) error {
+	if pos == nil {
+		return errors.New("chess: nil position")
+	}
+	pos = pos.copy()
+	pos.inCheck = isInCheck(pos)
+	rootMove := &Move{
+		position: pos,
+	}
+	g.rootMove = rootMove
+	g.currentMove = rootMove
+	g.pos = pos
+	g.outcome = NoOutcome
+	g.method = NoMethod
+	g.drawOffers = nil
+	g.repetitionCounts = nil
+	g.AddTagPair("SetUp", "1")
+	g.AddTagPair("FEN", pos.String())
+	g.evaluatePositionStatus()
+	return nil
+}
+
 // AddVariation adds a new variation to the game.
 // The parent move must be a move in the game or nil to add a variation to the root.
 func (g *Game) AddVariation(parent *Move, newMove *Move) {
 	parent.children = append(parent.children, newMove)
 	newMove.parent = parent
+	g.repetitionCounts = nil
+}
+
+// MaterializePositions walks the entire move tree (main line and
+// variations) and fills in any missing cached position by replaying
+// each line from the root.  The parser caches positions as it goes, so
+// this is a no-op for parsed games; it exists for moves grafted onto
+// the tree without positions, for example via AddVariation.  It is
+// idempotent and replays from the root's position, so from-position
+// games are handled.
+func (g *Game) MaterializePositions() {
+	if g.rootMove == nil || g.rootMove.position == nil {
+		return
+	}
+	var walk func(parent *Move)
+	walk = func(parent *Move) {
+		for _, child := range parent.children {
+			if child.position == nil && parent.position != nil {
+				child.position = parent.position.Update(child)
+			}
+			walk(child)
+		}
+	}
+	walk(g.rootMove)
 }
 
 // NavigateToMainLine navigates to the main line of the game.
@@ -242,6 +364,36 @@ func (g *Game) GoForward() bool {
 	return false
 }
 
+// StepBackwardN moves the current pointer back up to n half-moves,
+// stopping at the root, and returns the moves stepped over in the order
+// they were visited (most recent move first).  Fewer than n moves are
+// returned when the start of the game is reached.
+func (g *Game) StepBackwardN(n int) []*Move {
+	var visited []*Move
+	for range n {
+		m := g.currentMove
+		if !g.GoBack() {
+			break
+		}
+		visited = append(visited, m)
+	}
+	return visited
+}
+
+// StepForwardN advances the current pointer up to n half-moves along
+// the active line and returns the moves stepped onto in order.  Fewer
+// than n moves are returned when the end of the line is reached.
+func (g *Game) StepForwardN(n int) []*Move {
+	var visited []*Move
+	for range n {
+		if !g.GoForward() {
+			break
+		}
+		visited = append(visited, g.currentMove)
+	}
+	return visited
+}
+
 // IsAtStart returns true if the game is at the start.
 func (g *Game) IsAtStart() bool {
 	return g.currentMove == nil || g.currentMove == g.rootMove
@@ -257,6 +409,32 @@ func (g *Game) ValidMoves() []Move {
 	return g.pos.ValidMoves()
 }
 
+// NonDrawingMoves returns the legal moves in the current position
+// whose resulting position would not end the game in an automatic
+// draw: moves into a fivefold repetition, across the 75-move
+// threshold, into stalemate, or leaving insufficient material are
+// filtered out.  Bots that are winning use this to avoid stumbling
+// into a draw; when every legal move draws, the result is empty.
+func (g *Game) NonDrawingMoves() []Move {
+	// Prime the cached repetition counts for the mainline history.
+	g.numOfRepetitions()
+	var moves []Move
+	for _, m := range g.pos.ValidMoves() {
+		next := g.pos.Update(&m)
+		if next.halfMoveClock >= 150 {
+			continue
+		}
+		if g.repetitionCounts[next.repetitionHash()]+1 >= 5 {
+			continue
+		}
+		if next.Status() == Stalemate || !next.board.hasSufficientMaterial() {
+			continue
+		}
+		moves = append(moves, m)
+	}
+	return moves
+}
+
 // Moves returns the move history of the game following the main line.
 func (g *Game) Moves() []*Move {
 	if g.rootMove == nil {
@@ -279,6 +457,188 @@ func (g *Game) Moves() []*Move {
 	return moves[1:] // Skip the root move
 }
 
+// MoveView is a display-oriented snapshot of a single mainline move,
+// bundling the notations and metadata a viewer typically needs so
+// frontends don't have to re-derive positions in multiple passes.
+type MoveView struct {
+	SAN     string   // Standard algebraic notation, e.g. "Nf3"
+	UCI     string   // UCI notation, e.g. "g1f3"
+	FEN     string   // FEN of the position after the move
+	Comment string   // Comment attached to the move, if any
+	NAGs    []string // Numeric Annotation Glyphs, in order of appearance
+	Ply     int      // Half-move number, starting at 1
+	Number  int      // Full move number as displayed, e.g. 17 for "17..."
+	IsBlack bool     // True when the move was Black's
+}
+
+// defaultSignaturePlies is the number of half-moves OpeningSignature
+// uses when no count is given.
+const defaultSignaturePlies = 10
+
+// OpeningSignature returns a lightweight key for how the game started:
+// the first plies half-moves of the main line in UCI notation, joined
+// by spaces.  Games beginning with the same moves share a signature, so
+// it groups games by opening without a full ECO table.  A non-positive
+// plies selects the default of 10.  Games shorter than plies yield a
+// signature of what was played.
+func (g *Game) OpeningSignature(plies int) string {
+	if plies <= 0 {
+		plies = defaultSignaturePlies
+	}
+	var sb strings.Builder
+	current := g.rootMove
+	for i := 0; i < plies && current != nil && current.position != nil && len(current.children) > 0; i++ {
+		next := current.children[0]
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(UCINotation{}.Encode(current.position, next))
+		current = next
+	}
+	return sb.String()
+}
+
+// MoveList returns the main line of the game as MoveViews, produced in a
+// single traversal from the root.
+func (g *Game) MoveList() []MoveView {
+	views := make([]MoveView, 0)
+	current := g.rootMove
+	for current != nil && current.position != nil && len(current.children) > 0 {
+		next := current.children[0]
+		view := MoveView{
+			SAN:     AlgebraicNotation{}.Encode(current.position, next),
+			UCI:     UCINotation{}.Encode(current.position, next),
+			Comment: next.comments,
+			NAGs:    append([]string(nil), next.nags...),
+			Ply:     len(views) + 1,
+			Number:  current.position.moveCount,
+			IsBlack: current.position.turn == Black,
+		}
+		if next.position != nil {
+			view.FEN = next.position.String()
+		}
+		views = append(views, view)
+		current = next
+	}
+	return views
+}
+
+// TableFormat selects the row format used by WriteMoveTable.
+type TableFormat int
+
+const (
+	// TSVFormat emits tab-separated rows.
+	TSVFormat TableFormat = iota
+	// CSVFormat emits comma-separated rows with standard CSV quoting.
+	CSVFormat
+)
+
+// WriteMoveTable writes the main line of the game as one row per move,
+// preceded by a header row, in the given format.  The columns are the
+// ply (starting at 1), the FEN of the position the move was played from,
+// the move in UCI and SAN notation, and any stored %eval and %clk
+// command values.  The flat layout suits database and ML imports.
+func (g *Game) WriteMoveTable(w io.Writer, format TableFormat) error {
+	switch format {
+	case TSVFormat, CSVFormat:
+	default:
+		return errors.New("chess: invalid table format")
+	}
+
+	rows := [][]string{{"ply", "fen", "move_uci", "move_san", "eval", "clk"}}
+	current := g.rootMove
+	for current != nil && current.position != nil && len(current.children) > 0 {
+		next := current.children[0]
+		eval, _ := next.GetCommand("eval")
+		clk, _ := next.GetCommand("clk")
+		rows = append(rows, []string{
+			strconv.Itoa(len(rows)),
+			current.position.String(),
+			UCINotation{}.Encode(current.position, next),
+			AlgebraicNotation{}.Encode(current.position, next),
+			eval,
+			clk,
+		})
+		current = next
+	}
+
+	if format == CSVFormat {
+		cw := csv.NewWriter(w)
+		return cw.WriteAll(rows)
+	}
+	for _, row := range rows {
+		if _, err := fmt.Fprintln(w, strings.Join(row, "\t")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MateSequence returns the mainline moves from the current move whose
+// stored evaluations (see Move.Eval) announce a forced mate, e.g. for
+// rendering a "mate in 3" overlay from an annotated game.  The sequence
+// runs up to and including the mating move.  ok is false when the next
+// mainline move carries no mate eval, the stored mate distances don't
+// shrink consistently toward mate, or the line ends before mate is
+// delivered.
+func (g *Game) MateSequence() ([]*Move, bool) {
+	node := g.currentMove
+	if node == nil {
+		node = g.rootMove
+	}
+	if node == nil || len(node.children) == 0 {
+		return nil, false
+	}
+	if _, mate, ok := node.children[0].Eval(); !ok || mate == 0 {
+		return nil, false
+	}
+
+	seq := make([]*Move, 0)
+	prev := 0
+	for len(node.children) > 0 {
+		next := node.children[0]
+		seq = append(seq, next)
+		if next.position != nil && next.position.Status() == Checkmate {
+			return seq, true
+		}
+		_, mate, ok := next.Eval()
+		if !ok || mate == 0 {
+			// The announced mate fizzled out before being delivered.
+			return nil, false
+		}
+		if prev != 0 {
+			if (mate > 0) != (prev > 0) || abs(mate) > abs(prev) {
+				return nil, false
+			}
+		}
+		prev = mate
+		node = next
+	}
+	// The line ran out before the mating move was played.
+	return nil, false
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// CurrentMoveDisplay returns the move number of the current move as a
+// viewer would display it and whether the move was Black's, i.e. whether
+// it is rendered with an ellipsis ("17..." rather than "17.").  It is
+// derived from the position the move was played from, so it is correct
+// inside variations as well.  At the start of the game, before any move,
+// it returns 0 and false.
+func (g *Game) CurrentMoveDisplay() (int, bool) {
+	if g.currentMove == nil || g.currentMove == g.rootMove || g.currentMove.parent == nil {
+		return 0, false
+	}
+	before := g.currentMove.parent.position
+	return before.moveCount, before.turn == Black
+}
+
 // GetRootMove returns the root move of the game.
 func (g *Game) GetRootMove() *Move {
 	return g.rootMove
@@ -294,7 +654,10 @@ func (g *Game) Variations(move *Move) []*Move {
 }
 
 // Comments returns the comments for the game indexed by moves.
-// Comments returns the comments for the game indexed by moves.
+//
+// Deprecated: the slice-of-slices shape mirrors an old internal field
+// that is only populated by the legacy parser.  Use CommentsByMove to
+// read the comments attached to the move tree.
 func (g *Game) Comments() [][]string {
 	if g.comments == nil {
 		return [][]string{}
@@ -302,6 +665,94 @@ func (g *Game) Comments() [][]string {
 	return append([][]string(nil), g.comments...)
 }
 
+// CommentsByMove returns the comments stored in the move tree keyed by
+// move, covering the main line and all variations.  Moves without a
+// comment are omitted.
+func (g *Game) CommentsByMove() map[*Move]string {
+	byMove := map[*Move]string{}
+	var walk func(move *Move)
+	walk = func(move *Move) {
+		if move.comments != "" {
+			byMove[move] = move.comments
+		}
+		for _, child := range move.children {
+			walk(child)
+		}
+	}
+	if g.rootMove != nil {
+		walk(g.rootMove)
+	}
+	return byMove
+}
+
+// ForEachPosition calls fn for every move in the game tree with the
+// move and its cached position, covering the main line and all
+// variations in the order they appear.  The root move is skipped: its
+// position is the starting position, not the result of a move.
+func (g *Game) ForEachPosition(fn func(m *Move, pos *Position)) {
+	if fn == nil || g.rootMove == nil {
+		return
+	}
+	var walk func(move *Move)
+	walk = func(move *Move) {
+		if move != g.rootMove {
+			fn(move, move.position)
+		}
+		for _, child := range move.children {
+			walk(child)
+		}
+	}
+	walk(g.rootMove)
+}
+
+// MoveLogLine returns the single-line form of a move used by live-game
+// logs and broadcasts: the move number, the move in algebraic notation,
+// and the move's recorded time in parentheses, e.g. "17. Qd5 (0:01:23)".
+// The time comes from the move's [%clk] command, falling back to [%emt];
+// moves without either render without the parenthetical.  Returns an
+// empty string for a move without a recorded parent position.
+func (g *Game) MoveLogLine(m *Move) string {
+	if m == nil || m.parent == nil || m.parent.position == nil {
+		return ""
+	}
+	pre := m.parent.position
+	line := strconv.Itoa(pre.moveCount) + "."
+	if pre.Turn() == Black {
+		line += ".."
+	}
+	line += " " + AlgebraicNotation{}.Encode(pre, m)
+	if value, ok := m.GetCommand("clk"); ok {
+		if d, parsed := parseClockDuration(value); parsed {
+			return line + " (" + formatClockDuration(d) + ")"
+		}
+	}
+	if d, ok := m.ElapsedTime(); ok {
+		return line + " (" + formatClockDuration(d) + ")"
+	}
+	return line
+}
+
+// Replay walks the main line from the root, calling fn for each move
+// with the position after the move and the one-based ply within the
+// game.  From-position games replay from the root's position, so the
+// first ply is the first recorded move.  Replay stops at the first
+// error fn returns and returns it, letting the callback abort.
+func (g *Game) Replay(fn func(pos *Position, m *Move, ply int) error) error {
+	if fn == nil || g.rootMove == nil {
+		return nil
+	}
+	current := g.rootMove
+	ply := 0
+	for len(current.children) > 0 {
+		current = current.children[0]
+		ply++
+		if err := fn(current.position, current, ply); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Position returns the game's current position.
 func (g *Game) Position() *Position {
 	return g.pos
@@ -318,6 +769,42 @@ func (g *Game) CurrentPosition() *Position {
 	return g.currentMove.position
 }
 
+// InBook reports whether the book has any entry for the game's current
+// position.  Bots typically play from the book while this holds and
+// switch to search once it turns false.
+func (g *Game) InBook(book OpeningBook) bool {
+	if book == nil {
+		return false
+	}
+	hash, ok := positionBookHash(g.CurrentPosition())
+	if !ok {
+		return false
+	}
+	return len(book.FindMoves(hash)) > 0
+}
+
+// IsBookMove reports whether the last played move appears in the book
+// for the position it was played from.  False is returned before any
+// move has been played.
+func (g *Game) IsBookMove(book OpeningBook) bool {
+	m := g.currentMove
+	if book == nil || m == nil || m == g.rootMove ||
+		m.parent == nil || m.parent.position == nil {
+		return false
+	}
+	hash, ok := positionBookHash(m.parent.position)
+	if !ok {
+		return false
+	}
+	encoded := MoveToPolyglot(*m)
+	for _, entry := range book.FindMoves(hash) {
+		if entry.Move == encoded {
+			return true
+		}
+	}
+	return false
+}
+
 // Outcome returns the game outcome.
 func (g *Game) Outcome() Outcome {
 	return g.outcome
@@ -328,11 +815,96 @@ func (g *Game) Method() Method {
 	return g.method
 }
 
+// ResultText returns a human-readable sentence describing the game's
+// outcome and the method that produced it, e.g. "White wins by
+// checkmate." or "Draw by stalemate.".
+func (g *Game) ResultText() string {
+	switch g.outcome {
+	case WhiteWon, BlackWon:
+		winner := "White"
+		if g.outcome == BlackWon {
+			winner = "Black"
+		}
+		switch g.method {
+		case Checkmate:
+			return winner + " wins by checkmate."
+		case Resignation:
+			return winner + " wins by resignation."
+		default:
+			return winner + " wins."
+		}
+	case Draw:
+		switch g.method {
+		case Stalemate:
+			return "Draw by stalemate."
+		case DrawOffer:
+			return "Draw by agreement."
+		case ThreefoldRepetition:
+			return "Draw by threefold repetition."
+		case FivefoldRepetition:
+			return "Draw by fivefold repetition."
+		case FiftyMoveRule:
+			return "Draw by the fifty-move rule."
+		case SeventyFiveMoveRule:
+			return "Draw by the seventy-five-move rule."
+		case InsufficientMaterial:
+			return "Draw by insufficient material."
+		default:
+			return "Draw."
+		}
+	default:
+		return "Game in progress."
+	}
+}
+
+// Raw returns the original PGN text of the game as read by the scanner.
+// It is only populated when the game was produced by a Scanner created
+// with WithPreserveRaw; otherwise it returns the empty string.
+func (g *Game) Raw() string {
+	return g.raw
+}
+
 // FEN returns the FEN notation of the current position.
 func (g *Game) FEN() string {
 	return g.pos.String()
 }
 
+// Checksum returns a SHA-256 digest of the game's canonical content: the
+// starting position, the mainline moves, and the outcome.  Tags,
+// comments, annotations, and variations are ignored, so the checksum is
+// stable across serialization round trips and formatting differences.
+func (g *Game) Checksum() [sha256.Size]byte {
+	var sb strings.Builder
+	sb.WriteString(g.rootMove.position.String())
+	for _, m := range g.Moves() {
+		sb.WriteByte(' ')
+		sb.WriteString(m.String())
+	}
+	sb.WriteByte(' ')
+	sb.WriteString(string(g.outcome))
+	return sha256.Sum256([]byte(sb.String()))
+}
+
+// ShortID returns a short shareable identifier for the game in the style
+// of lichess and chess.com game IDs: eight base62 characters derived
+// from Checksum.  Like the checksum it is stable across serialization
+// round trips, making it suitable for dedup keys and URLs.
+func (g *Game) ShortID() string {
+	const (
+		base62Chars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+		base        = 62
+		idLen       = 8
+	)
+	sum := g.Checksum()
+	v := binary.BigEndian.Uint64(sum[:8])
+	id := make([]byte, idLen)
+	for i := idLen - 1; i >= 0; i-- {
+		id[i] = base62Chars[v%base]
+		v /= base
+	}
+	return string(id)
+}
+
 // String implements the fmt.Stringer interface and returns
 // the game's PGN.
 func (g *Game) String() string {
@@ -353,7 +925,7 @@ func (g *Game) String() string {
 
 	// Write tag pairs.
 	for _, tagPair := range tagPairList {
-		sb.WriteString(fmt.Sprintf("[%s \"%s\"]\n", tagPair.Key, tagPair.Value))
+		sb.WriteString(fmt.Sprintf("[%s \"%s\"]\n", tagPair.Key, escapeTagValue(tagPair.Value)))
 	}
 
 	// Append empty line after tag pairs as per definition
@@ -374,7 +946,7 @@ func (g *Game) String() string {
 	if needTrailingSpace {
 		sb.WriteString(" ")
 	}
-	sb.WriteString(g.Outcome().String()) // outcomeString() returns the result as a string (e.g. "1-0")
+	sb.WriteString(g.outcome.pgnString()) // the result as a string (e.g. "1-0")
 	return sb.String()
 }
 
@@ -384,6 +956,14 @@ type sortableTagPair struct {
 	Value string
 }
 
+// escapeTagValue escapes the quote and backslash characters in a tag
+// value as required by the PGN spec, so user data cannot produce
+// invalid PGN.  readTagValue performs the matching unescape.
+func escapeTagValue(v string) string {
+	v = strings.ReplaceAll(v, `\`, `\\`)
+	return strings.ReplaceAll(v, `"`, `\"`)
+}
+
 // Compares two tags to determine in which order they should be brought up
 func cmpTags(a, b sortableTagPair) int {
 	// Don't re-order duplicate keys
@@ -434,7 +1014,7 @@ func cmpTags(a, b sortableTagPair) int {
 //	isRoot - true if the current move is the root move of a game, affecting formatting details.
 //
 // The function recurses through the move tree, writing the main line first and then processing any additional variations,
-// ensuring that the output adheres to standard PGN conventions. Future enhancements may include support for all NAG values.
+// ensuring that the output adheres to standard PGN conventions.
 // the function returns whether or not a trailing space was added to the output
 func writeMoves(node *Move, moveNum int, isWhite bool, sb *strings.Builder,
 	subVariation, closedVariation, isRoot bool) bool {
@@ -457,18 +1037,26 @@ func writeMoves(node *Move, moveNum int, isWhite bool, sb *strings.Builder,
 		currentMove = node.children[0]
 	}
 
-	writeMoveNumber(moveNum, isWhite, subVariation, closedVariation, isRoot, sb)
+	// A pre-move comment forces the move number so the comment stays
+	// attached to the right move on re-parse.
+	hasPreComment := currentMove.preComments != ""
+	writeMoveNumber(moveNum, isWhite, subVariation, closedVariation,
+		isRoot || hasPreComment, sb)
+
+	if hasPreComment {
+		sb.WriteString("{" + currentMove.preComments + "} ")
+	}
 
 	// Encode the move using your AlgebraicNotation.
 	writeMoveEncoding(node, currentMove, subVariation, sb)
 
+	writeNAGs(currentMove, sb)
+
 	// Append a comment if present.
 	writeComments(currentMove, sb)
 
 	writeCommands(currentMove, sb)
 
-	//TODO: Add support for all nags values in the future
-
 	if len(node.children) > 1 || len(currentMove.children) > 0 {
 		sb.WriteString(" ")
 	}
@@ -517,6 +1105,17 @@ func writeMoveEncoding(node *Move, currentMove *Move, subVariation bool, sb *str
 	}
 }
 
+func writeNAGs(move *Move, sb *strings.Builder) {
+	for _, nag := range move.nags {
+		// Traditional suffix annotations (e.g. "?!") attach directly to the
+		// move; $-form NAGs are separated by a space.
+		if strings.HasPrefix(nag, "$") {
+			sb.WriteString(" ")
+		}
+		sb.WriteString(nag)
+	}
+}
+
 func writeComments(move *Move, sb *strings.Builder) {
 	if move.comments != "" {
 		sb.WriteString(" {" + move.comments + "}")
@@ -573,6 +1172,44 @@ func (g *Game) UnmarshalText(text []byte) error {
 	return nil
 }
 
+// OnMove registers a callback invoked after a move has been applied to
+// the game.  The callback fires once the game state is fully updated, so
+// it may safely inspect the game's position and outcome.  Callbacks are
+// invoked in registration order.
+func (g *Game) OnMove(fn func(*Move)) {
+	if fn != nil {
+		g.onMoveHooks = append(g.onMoveHooks, fn)
+	}
+}
+
+// OnGameEnd registers a callback invoked when the game receives an
+// outcome, whether by a move (checkmate, stalemate, automatic draw),
+// Draw(), or Resign().  The callback fires once the game state is fully
+// updated.  Callbacks are invoked in registration order.
+func (g *Game) OnGameEnd(fn func(Outcome, Method)) {
+	if fn != nil {
+		g.onGameEndHooks = append(g.onGameEndHooks, fn)
+	}
+}
+
+// fireMoveHooks invokes the registered move callbacks.
+func (g *Game) fireMoveHooks(move *Move) {
+	for _, fn := range g.onMoveHooks {
+		fn(move)
+	}
+}
+
+// fireGameEndHooks invokes the registered game-end callbacks if the game
+// has an outcome.
+func (g *Game) fireGameEndHooks() {
+	if g.outcome.Ongoing() {
+		return
+	}
+	for _, fn := range g.onGameEndHooks {
+		fn(g.outcome, g.method)
+	}
+}
+
 // Draw attempts to draw the game by the given method.  If the
 // method is valid, then the game is updated to a draw by that
 // method.  If the method isn't valid then an error is returned.
@@ -595,13 +1232,65 @@ func (g *Game) Draw(method Method) error {
 	}
 	g.outcome = Draw
 	g.method = method
+	g.syncResultTag()
+	g.fireGameEndHooks()
+	return nil
+}
+
+// OfferDraw records a draw offer by the given color so repeat offers can
+// be rate limited.  It returns an error when CanOfferDraw would reject
+// the offer.  Accepting an outstanding offer is done with
+// Draw(DrawOffer).
+func (g *Game) OfferDraw(color Color) error {
+	if !g.CanOfferDraw(color) {
+		return errors.New("chess: color cannot offer a draw")
+	}
+	if g.drawOffers == nil {
+		g.drawOffers = make(map[Color]int, 2)
+	}
+	// The offer stands until the opponent has moved the configured
+	// number of times; translate that into plies from now.
+	plies := 2 * g.drawOfferCooldownMoves()
+	if g.pos.turn == color.Other() {
+		plies--
+	}
+	g.drawOffers[color] = g.pos.Ply() + plies
 	return nil
 }
 
+// CanOfferDraw reports whether the given color may offer a draw.  The
+// game must still be in progress, and after offering a color may not
+// offer again until the opponent has moved — or until the number of
+// opponent moves configured with SetDrawOfferCooldown have been played.
+// This lets servers prevent draw-offer spamming.
+func (g *Game) CanOfferDraw(color Color) bool {
+	if !g.outcome.Ongoing() || (color != White && color != Black) {
+		return false
+	}
+	eligibleAt, ok := g.drawOffers[color]
+	if !ok {
+		return true
+	}
+	return g.pos.Ply() >= eligibleAt
+}
+
+// SetDrawOfferCooldown sets how many opponent moves must be played after
+// a draw offer before the same color may offer again.  The default is 1.
+func (g *Game) SetDrawOfferCooldown(moves int) {
+	g.drawOfferCooldown = moves
+}
+
+func (g *Game) drawOfferCooldownMoves() int {
+	if g.drawOfferCooldown < 1 {
+		return 1
+	}
+	return g.drawOfferCooldown
+}
+
 // Resign resigns the game for the given color.  If the game has
 // already been completed then the game is not updated.
 func (g *Game) Resign(color Color) {
-	if g.outcome != NoOutcome || color == NoColor {
+	if !g.outcome.Ongoing() || color == NoColor {
 		return
 	}
 	if color == White {
@@ -610,6 +1299,66 @@ func (g *Game) Resign(color Color) {
 		g.outcome = WhiteWon
 	}
 	g.method = Resignation
+	g.syncResultTag()
+	g.fireGameEndHooks()
+}
+
+// SetResult sets the game's outcome and updates the "Result" tag pair to
+// match, keeping exported tag pairs consistent with the outcome.
+func (g *Game) SetResult(outcome Outcome) {
+	g.outcome = outcome
+	g.AddTagPair("Result", outcome.String())
+}
+
+// syncResultTag updates an existing "Result" tag pair to match the
+// game's outcome.  Games without a Result tag are left untouched so that
+// tag-free games keep exporting bare movetext.
+func (g *Game) syncResultTag() {
+	if _, ok := g.tagPairs["Result"]; ok {
+		g.tagPairs["Result"] = g.outcome.pgnString()
+	}
+}
+
+// ResultConsistency checks that the game's declared result is consistent
+// with its final position.  It compares the "Result" tag pair (when
+// present), the outcome parsed from the movetext terminator, and the
+// outcome implied by the final position of the main line.  It returns
+// false with a human-readable detail when they disagree, e.g. a "1-0"
+// result on a stalemated board.  Results the board alone cannot verify,
+// such as wins by resignation, are considered consistent.
+func (g *Game) ResultConsistency() (bool, string) {
+	declared := g.outcome
+	if tag := g.GetTagPair("Result"); tag != "" && tag != declared.String() {
+		return false, fmt.Sprintf("result tag %s does not match game outcome %s", tag, declared)
+	}
+
+	// Walk the main line to its final position.
+	final := g.rootMove
+	for len(final.children) > 0 {
+		final = final.children[0]
+	}
+	pos := g.pos
+	if final.position != nil {
+		pos = final.position
+	}
+	switch pos.Status() {
+	case Checkmate:
+		expected := WhiteWon
+		if pos.Turn() == White {
+			expected = BlackWon
+		}
+		if declared != expected {
+			return false, fmt.Sprintf("final position is checkmate (%s) but game outcome is %s", expected, declared)
+		}
+	case Stalemate:
+		if declared != Draw {
+			return false, fmt.Sprintf("final position is stalemate but game outcome is %s", declared)
+		}
+	case NoMethod:
+		// A decisive or drawn result without a terminal position can come
+		// from resignation or agreement and is not flagged.
+	}
+	return true, ""
 }
 
 // EligibleDraws returns valid inputs for the Draw() method.
@@ -647,6 +1396,146 @@ func (g *Game) GetTagPair(k string) string {
 	return g.tagPairs[k]
 }
 
+// Orientation returns the board orientation requested by an
+// [Orientation "white"|"black"] tag, as exported by lichess study
+// chapters.  It defaults to White when the tag is absent or holds any
+// other value.
+func (g *Game) Orientation() Color {
+	if strings.EqualFold(g.GetTagPair("Orientation"), "black") {
+		return Black
+	}
+	return White
+}
+
+// IsGamebook returns true if the game carries a
+// [ChapterMode "gamebook"] tag, which lichess studies use to mark
+// interactive lesson chapters.
+func (g *Game) IsGamebook() bool {
+	return strings.EqualFold(g.GetTagPair("ChapterMode"), "gamebook")
+}
+
+// Annotator returns the value of the [Annotator "..."] tag, naming who
+// annotated the game.  Returns an empty string when the tag is absent.
+func (g *Game) Annotator() string {
+	return g.GetTagPair("Annotator")
+}
+
+// Opening returns the value of the [Opening "..."] tag, the opening
+// name assigned by the exporter.  Returns an empty string when the tag
+// is absent.
+func (g *Game) Opening() string {
+	return g.GetTagPair("Opening")
+}
+
+// ECO returns the value of the [ECO "..."] tag, the game's
+// Encyclopaedia of Chess Openings code.  Returns an empty string when
+// the tag is absent.
+func (g *Game) ECO() string {
+	return g.GetTagPair("ECO")
+}
+
+// DeclaredPlyCount returns the half-move count declared by a
+// [PlyCount "N"] tag, as written by ChessBase and some databases.  ok
+// is false when the tag is absent or not a number.
+func (g *Game) DeclaredPlyCount() (int, bool) {
+	value := strings.TrimSpace(g.GetTagPair("PlyCount"))
+	if value == "" {
+		return 0, false
+	}
+	count, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, false
+	}
+	return count, true
+}
+
+// SetPlyCountTag adds or updates the [PlyCount "N"] tag with the actual
+// length of the main line, for interoperability with tools that rely on
+// the tag.  The tag is not emitted unless this is called.
+func (g *Game) SetPlyCountTag() {
+	g.AddTagPair("PlyCount", strconv.Itoa(len(g.Moves())))
+}
+
+// PlyCountConsistency returns false and a description when a declared
+// PlyCount tag does not match the actual main line length, which flags
+// truncated or corrupt movetext.  Games without the tag are consistent.
+func (g *Game) PlyCountConsistency() (bool, string) {
+	declared, ok := g.DeclaredPlyCount()
+	if !ok {
+		return true, ""
+	}
+	if actual := len(g.Moves()); declared != actual {
+		return false, fmt.Sprintf("PlyCount tag %d does not match %d mainline half-moves", declared, actual)
+	}
+	return true, ""
+}
+
+// RoundNumber returns the round from the [Round "..."] tag.  Team-match
+// PGNs use the dotted "round.board" form ("3.2" is board 2 of round 3);
+// plain integers return a board of 0.  ok is false when the tag is
+// absent, unknown ("-" or "?"), or not numeric.
+func (g *Game) RoundNumber() (round int, board int, ok bool) {
+	value := strings.TrimSpace(g.GetTagPair("Round"))
+	if value == "" || value == "-" || value == "?" {
+		return 0, 0, false
+	}
+	roundStr, boardStr, dotted := strings.Cut(value, ".")
+	round, err := strconv.Atoi(roundStr)
+	if err != nil {
+		return 0, 0, false
+	}
+	if dotted {
+		board, err = strconv.Atoi(boardStr)
+		if err != nil {
+			return 0, 0, false
+		}
+	}
+	return round, board, true
+}
+
+// parseElo parses the value of a WhiteElo or BlackElo tag.  Absent or
+// unknown ratings ("", "?", "-") return ok false; a trailing
+// provisional marker such as "2400?" is tolerated.
+func parseElo(v string) (int, bool) {
+	v = strings.TrimSuffix(strings.TrimSpace(v), "?")
+	if v == "" || v == "-" {
+		return 0, false
+	}
+	elo, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, false
+	}
+	return elo, true
+}
+
+// WhiteElo returns White's rating from the [WhiteElo "..."] tag.
+// Returns ok false when the tag is absent, unknown ("?" or "-"), or
+// not a number.
+func (g *Game) WhiteElo() (int, bool) {
+	return parseElo(g.GetTagPair("WhiteElo"))
+}
+
+// BlackElo returns Black's rating from the [BlackElo "..."] tag.
+// Returns ok false when the tag is absent, unknown ("?" or "-"), or
+// not a number.
+func (g *Game) BlackElo() (int, bool) {
+	return parseElo(g.GetTagPair("BlackElo"))
+}
+
+// AverageElo returns the mean of the two players' ratings.  Returns ok
+// false unless both WhiteElo and BlackElo parse.
+func (g *Game) AverageElo() (int, bool) {
+	white, ok := g.WhiteElo()
+	if !ok {
+		return 0, false
+	}
+	black, ok := g.BlackElo()
+	if !ok {
+		return 0, false
+	}
+	return (white + black) / 2, true
+}
+
 // RemoveTagPair removes the tag pair for the given key and
 // returns true if a tag pair was removed.
 func (g *Game) RemoveTagPair(k string) bool {
@@ -658,8 +1547,69 @@ func (g *Game) RemoveTagPair(k string) bool {
 	return false
 }
 
+// SetVariant selects the variant whose terminal conditions the game
+// enforces and re-evaluates the current position against them.
+// VariantStandard is the default.
+func (g *Game) SetVariant(v Variant) {
+	g.variant = v
+	g.evaluatePositionStatus()
+}
+
+// Variant returns the variant whose terminal conditions the game
+// enforces.
+func (g *Game) Variant() Variant {
+	return g.variant
+}
+
+// CheckCount returns the number of checks the given color has delivered
+// along the main line.  Three checks is the three-check win condition;
+// the count is maintained for standard games too.
+func (g *Game) CheckCount(color Color) int {
+	count := 0
+	for _, m := range g.Moves() {
+		if m.HasTag(Check) && m.position != nil && m.position.Turn() == color.Other() {
+			count++
+		}
+	}
+	return count
+}
+
+// variantStatus evaluates the active variant's terminal conditions
+// against the current position.  ok is false when the game continues
+// (or no variant is active).
+func (g *Game) variantStatus() (Outcome, Method, bool) {
+	switch g.variant {
+	case VariantThreeCheck:
+		for _, color := range []Color{White, Black} {
+			if g.CheckCount(color) >= 3 {
+				outcome := WhiteWon
+				if color == Black {
+					outcome = BlackWon
+				}
+				return outcome, ThirdCheck, true
+			}
+		}
+	case VariantKingOfTheHill:
+		hill := bbForSquare(D4) | bbForSquare(E4) | bbForSquare(D5) | bbForSquare(E5)
+		if bbForSquare(g.pos.board.whiteKingSq)&hill != 0 {
+			return WhiteWon, KingInCenter, true
+		}
+		if bbForSquare(g.pos.board.blackKingSq)&hill != 0 {
+			return BlackWon, KingInCenter, true
+		}
+	case VariantStandard:
+	}
+	return NoOutcome, NoMethod, false
+}
+
 // evaluatePositionStatus updates the game's outcome and method based on the current position.
 func (g *Game) evaluatePositionStatus() {
+	if outcome, method, over := g.variantStatus(); over {
+		g.outcome = outcome
+		g.method = method
+		g.syncResultTag()
+		return
+	}
 	method := g.pos.Status()
 	if method == Stalemate {
 		g.method = Stalemate
@@ -671,7 +1621,11 @@ func (g *Game) evaluatePositionStatus() {
 			g.outcome = BlackWon
 		}
 	}
+	// Checkmate and stalemate take precedence over the automatic draws
+	// below: a move that delivers mate as it crosses the 75-move
+	// threshold (or would repeat a position) ends the game decisively.
 	if g.outcome != NoOutcome {
+		g.syncResultTag()
 		return
 	}
 
@@ -692,6 +1646,8 @@ func (g *Game) evaluatePositionStatus() {
 		g.outcome = Draw
 		g.method = InsufficientMaterial
 	}
+
+	g.syncResultTag()
 }
 
 // copy copies the game state from the given game.
@@ -706,7 +1662,18 @@ func (g *Game) copy(game *Game) {
 	g.outcome = game.outcome
 	g.method = game.method
 	g.comments = game.Comments()
+	g.raw = game.raw
+	g.drawOfferCooldown = game.drawOfferCooldown
+	if game.drawOffers != nil {
+		g.drawOffers = make(map[Color]int, len(game.drawOffers))
+		for color, ply := range game.drawOffers {
+			g.drawOffers[color] = ply
+		}
+	}
+	// Rebuilt on demand; the copy must not share the original's map.
+	g.repetitionCounts = nil
 	g.ignoreAutomaticDraws = game.ignoreAutomaticDraws
+	g.variant = game.variant
 }
 
 // Clone returns a deep copy of the game.
@@ -717,6 +1684,68 @@ func (g *Game) Clone() *Game {
 	return ret
 }
 
+// CloneToCurrent returns a deep copy of the game truncated at the
+// current move.  The copy contains only the path from the root to the
+// current move; forward moves and sibling variations are not carried
+// over.  When forward moves are dropped the copy's outcome is reset, as
+// the declared result no longer follows from its movetext.  This is
+// useful for exploring a position in isolation.
+func (g *Game) CloneToCurrent() *Game {
+	ret := g.Clone()
+
+	var path []*Move
+	for m := g.currentMove; m != nil && m != g.rootMove; m = m.parent {
+		path = append(path, m)
+	}
+
+	rootMove := &Move{position: g.rootMove.position.copy()}
+	cur := rootMove
+	for i := len(path) - 1; i >= 0; i-- {
+		child := path[i].Clone()
+		child.parent = cur
+		cur.children = []*Move{child}
+		cur = child
+	}
+
+	ret.rootMove = rootMove
+	ret.currentMove = cur
+	ret.pos = cur.position
+	if len(g.currentMove.children) > 0 {
+		ret.outcome = NoOutcome
+		ret.method = NoMethod
+		ret.syncResultTag()
+	}
+	return ret
+}
+
+// StripAnnotations returns a deep copy of the game containing only the
+// main line moves.  Comments, NAGs, command annotations (e.g. %clk and
+// %eval), and variations are all removed; tag pairs and the result are
+// retained.  The original game is not modified.
+func (g *Game) StripAnnotations() *Game {
+	ret := g.Clone()
+	rootMove := &Move{position: g.rootMove.position.copy()}
+	cur := rootMove
+
+	for _, m := range g.Moves() {
+		child := m.Clone()
+		child.comments = ""
+		child.nags = nil
+		child.command = make(map[string]string)
+		child.parent = cur
+
+		cur.children = []*Move{child}
+		cur = child
+	}
+
+	ret.rootMove = rootMove
+	ret.currentMove = cur
+	ret.pos = cur.position
+	ret.comments = nil
+
+	return ret
+}
+
 // Positions returns all positions in the game in the main line.
 // This includes the starting position and all positions after each move.
 func (g *Game) Positions() []*Position {
@@ -736,17 +1765,35 @@ func (g *Game) Positions() []*Position {
 	return positions
 }
 
+// numOfRepetitions returns how many mainline positions repeat the
+// current position.  The counts are kept in a position-hash map so the
+// check after each pushed move is O(1) instead of rescanning the whole
+// line; pushes at the end of the main line patch the map in place and
+// any other tree change drops it for rebuilding on demand.
 func (g *Game) numOfRepetitions() int {
-	count := 0
-	for _, pos := range g.Positions() {
-		if pos == nil {
-			continue
-		}
-		if g.pos.samePosition(pos) {
-			count++
+	if g.repetitionCounts == nil {
+		g.repetitionCounts = make(map[uint64]int)
+		for _, pos := range g.Positions() {
+			if pos == nil {
+				continue
+			}
+			g.repetitionCounts[pos.repetitionHash()]++
 		}
 	}
-	return count
+	return g.repetitionCounts[g.pos.repetitionHash()]
+}
+
+// noteMainlinePush patches the cached repetition counts for a newly
+// pushed move that extends the end of the main line.  Moves landing
+// anywhere else leave the main line unchanged, so the cache stays valid
+// without an update.
+func (g *Game) noteMainlinePush(move *Move) {
+	if g.repetitionCounts == nil || move.position == nil {
+		return
+	}
+	if len(move.children) == 0 && isMainLine(move) {
+		g.repetitionCounts[move.position.repetitionHash()]++
+	}
 }
 
 // PushMoveOptions contains options for pushing a move to the game
@@ -778,10 +1825,59 @@ func (g *Game) PushMove(algebraicMove string, options *PushMoveOptions) error {
 
 	g.updatePosition(move)
 	g.currentMove = move
+	if existingMove == nil {
+		g.noteMainlinePush(move)
+	}
 
 	// Add this line to evaluate the position after the move
 	g.evaluatePositionStatus()
 
+	g.fireMoveHooks(move)
+	g.fireGameEndHooks()
+
+	return nil
+}
+
+// PushMoves applies a sequence of moves in algebraic notation to the
+// game atomically: either every move is applied, or, if any move is
+// invalid, the game is restored to the state it was in before the call
+// so it isn't left half-updated.
+//
+// Example:
+//
+//	err := game.PushMoves([]string{"e4", "e5", "Nf3"}, nil)
+func (g *Game) PushMoves(sans []string, options *PushMoveOptions) error {
+	savedMove := g.currentMove
+	savedPos := g.pos
+	savedOutcome := g.outcome
+	savedMethod := g.method
+
+	// Snapshot each parent's child list before pushing into it so both
+	// added moves and reordered variations can be rolled back.
+	type snapshot struct {
+		parent   *Move
+		children []*Move
+	}
+	snapshots := make([]snapshot, 0, len(sans))
+
+	for i, san := range sans {
+		snapshots = append(snapshots, snapshot{
+			parent:   g.currentMove,
+			children: append([]*Move(nil), g.currentMove.children...),
+		})
+		if err := g.PushMove(san, options); err != nil {
+			for j := len(snapshots) - 1; j >= 0; j-- {
+				snapshots[j].parent.children = snapshots[j].children
+			}
+			g.currentMove = savedMove
+			g.pos = savedPos
+			g.outcome = savedOutcome
+			g.method = savedMethod
+			g.repetitionCounts = nil
+			g.syncResultTag()
+			return fmt.Errorf("chess: invalid move %q at index %d: %w", san, i, err)
+		}
+	}
 	return nil
 }
 
@@ -827,12 +1923,142 @@ func (g *Game) Move(move *Move, options *PushMoveOptions) error {
 
 	g.updatePosition(move)
 	g.currentMove = move
+	if existingMove == nil {
+		g.noteMainlinePush(move)
+	}
 
 	g.evaluatePositionStatus()
 
+	g.fireMoveHooks(move)
+	g.fireGameEndHooks()
+
+	return nil
+}
+
+// ApplyPV pushes the moves of an engine principal variation onto the game
+// starting from the current position, e.g. the PV reported in uci.Info.
+// With asVariation false each move is forced onto the mainline; with
+// asVariation true the line is added as a variation branch.  Where the PV
+// coincides with existing children they are reused, and the line branches
+// off where it diverges.  Each move is validated as it is applied; if a
+// move is illegal the moves before it stay applied and the illegal move
+// is returned along with an error.  The current move is left at the end
+// of the applied line.
+func (g *Game) ApplyPV(pv []*Move, asVariation bool) (*Move, error) {
+	for i, pvMove := range pv {
+		var matched *Move
+		for _, vm := range g.pos.ValidMoves() {
+			if vm.s1 == pvMove.s1 && vm.s2 == pvMove.s2 && vm.promo == pvMove.promo {
+				m := vm
+				matched = &m
+				break
+			}
+		}
+		if matched == nil {
+			return pvMove, fmt.Errorf("chess: pv move %d (%s) is not legal in position %s",
+				i+1, pvMove.String(), g.pos.String())
+		}
+
+		if existing := g.findExistingMove(matched); existing != nil {
+			if !asVariation && existing != g.currentMove.children[0] {
+				g.reorderMoveToFront(existing)
+			}
+			g.currentMove = existing
+			g.pos = existing.position.copy()
+			continue
+		}
+
+		matched.parent = g.currentMove
+		g.addNewMove(matched, !asVariation)
+		g.updatePosition(matched)
+		g.currentMove = matched
+		g.noteMainlinePush(matched)
+	}
+	if !asVariation {
+		g.evaluatePositionStatus()
+	}
+	return nil, nil
+}
+
+// ReplaceCurrentMove replaces the current move with the move given in
+// algebraic notation, parsed against the position before the current move.
+// The replacement keeps the old move's comments, NAGs, commands, number,
+// and variations, and the cached positions of all descendant moves are
+// recomputed. It returns an error if the game has no current move or the
+// notation is not a valid move in the parent position.
+//
+// Example:
+//
+//	game.GoBack()
+//	err := game.ReplaceCurrentMove("c5")
+func (g *Game) ReplaceCurrentMove(san string) error {
+	if g.currentMove == nil || g.currentMove == g.rootMove {
+		return errors.New("chess: no current move to replace")
+	}
+
+	parent := g.currentMove.parent
+	newMove, err := AlgebraicNotation{}.Decode(parent.position, san)
+	if err != nil {
+		return err
+	}
+
+	old := g.currentMove
+	newMove.parent = parent
+	newMove.children = old.children
+	newMove.comments = old.comments
+	newMove.nags = old.nags
+	newMove.command = old.command
+	newMove.number = old.number
+	for i, child := range parent.children {
+		if child == old {
+			parent.children[i] = newMove
+			break
+		}
+	}
+	for _, child := range newMove.children {
+		child.parent = newMove
+	}
+
+	g.currentMove = newMove
+	g.RecomputePositions(newMove)
 	return nil
 }
 
+// RecomputePositions re-derives the cached position of from and every move
+// below it by replaying each move against its parent's position.  Move tags
+// are re-derived as well since captures, checks, and en passant depend on
+// the position the move is played from.  Editing operations that
+// restructure the move tree call this to keep cached positions consistent;
+// it is exported so callers that edit moves directly can do the same.
+// Passing nil or the root move recomputes the whole tree.
+func (g *Game) RecomputePositions(from *Move) {
+	if from == nil {
+		from = g.rootMove
+	}
+	if from.parent != nil {
+		from.tags = 0
+		addTags(from, from.parent.position)
+		from.position = from.parent.position.Update(from)
+	}
+	recomputeDescendantPositions(from)
+	if g.currentMove != nil && g.currentMove.position != nil {
+		g.pos = g.currentMove.position.copy()
+	}
+	g.repetitionCounts = nil
+}
+
+// recomputeDescendantPositions re-derives the cached positions of every
+// move below the given move by replaying each one against its parent's
+// position.
+func recomputeDescendantPositions(move *Move) {
+	for _, child := range move.children {
+		child.tags = 0
+		addTags(child, move.position)
+		child.position = move.position.Update(child)
+		recomputeDescendantPositions(child)
+	}
+}
+
 func (g *Game) parseAndValidateMove(algebraicMove string) (*Move, error) {
 	tokens, err := TokenizeGame(&GameScanned{Raw: algebraicMove})
 	if err != nil {
@@ -880,6 +2106,8 @@ func (g *Game) addOrReorderMove(move, existingMove *Move, forceMainline bool) {
 }
 
 func (g *Game) reorderMoveToFront(move *Move) {
+	// Promoting a variation changes the main line.
+	g.repetitionCounts = nil
 	children := g.currentMove.children
 	for i, child := range children {
 		if child == move {
@@ -892,6 +2120,10 @@ func (g *Game) reorderMoveToFront(move *Move) {
 
 func (g *Game) addNewMove(move *Move, forceMainline bool) {
 	if forceMainline {
+		if len(g.currentMove.children) > 0 {
+			// Displacing an existing first child changes the main line.
+			g.repetitionCounts = nil
+		}
 		g.currentMove.children = append([]*Move{move}, g.currentMove.children...)
 	} else {
 		g.currentMove.children = append(g.currentMove.children, move)
@@ -905,6 +2137,86 @@ func (g *Game) updatePosition(move *Move) {
 	}
 }
 
+// Flipped returns a color-reversed copy of the game: every position is
+// mirrored across the horizontal center line with the colors, side to
+// move, castling rights, and en passant square swapped (see
+// Position.Transform), every move including variations is mapped to its
+// mirrored counterpart, and the result and player tags are swapped.
+// Flipping twice restores the original game.  This is useful for
+// studying a game from the other side's perspective and for generating
+// color-symmetric training data.
+func (g *Game) Flipped() (*Game, error) {
+	flippedRoot, err := g.rootMove.position.Transform(VerticalFlip)
+	if err != nil {
+		return nil, err
+	}
+	ret := NewGame()
+	if err := ret.SetPosition(flippedRoot); err != nil {
+		return nil, err
+	}
+
+	var clone func(src, dst *Move) error
+	clone = func(src, dst *Move) error {
+		for _, child := range src.children {
+			pos, err := child.position.Transform(VerticalFlip)
+			if err != nil {
+				return err
+			}
+			fm := &Move{
+				s1:        NewSquare(child.s1.File(), 7-child.s1.Rank()),
+				s2:        NewSquare(child.s2.File(), 7-child.s2.Rank()),
+				promo:     child.promo,
+				tags:      child.tags,
+				checkType: child.checkType,
+				nags:      append([]string(nil), child.nags...),
+				comments:  child.comments,
+				number:    uint(dst.position.moveCount),
+				parent:    dst,
+				position:  pos,
+			}
+			if len(child.command) > 0 {
+				fm.command = make(map[string]string, len(child.command))
+				for k, v := range child.command {
+					fm.command[k] = v
+				}
+			}
+			dst.children = append(dst.children, fm)
+			if err := clone(child, fm); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := clone(g.rootMove, ret.rootMove); err != nil {
+		return nil, err
+	}
+
+	// Point at the main line tail, as a freshly parsed game would.
+	ret.currentMove = ret.rootMove
+	for len(ret.currentMove.children) > 0 {
+		ret.currentMove = ret.currentMove.children[0]
+	}
+	ret.pos = ret.currentMove.position.copy()
+
+	switch g.outcome {
+	case WhiteWon:
+		ret.outcome = BlackWon
+	case BlackWon:
+		ret.outcome = WhiteWon
+	default:
+		ret.outcome = g.outcome
+	}
+	ret.method = g.method
+	if white, black := g.GetTagPair("White"), g.GetTagPair("Black"); white != "" || black != "" {
+		ret.AddTagPair("White", black)
+		ret.AddTagPair("Black", white)
+	}
+	if g.GetTagPair("Result") != "" {
+		ret.AddTagPair("Result", ret.outcome.pgnString())
+	}
+	return ret, nil
+}
+
 // Split takes a Game with a main line and 0 or more variations and returns a
 // slice of Games (one for each variation), each containing exactly only a main
 // line and 0 variations
@@ -3,8 +3,11 @@ package chess
 import (
 	"errors"
 	"log"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestCheckmate(t *testing.T) {
@@ -1300,3 +1303,1486 @@ func TestGameSplitNoVar(t *testing.T) {
 	pgn := "[Event \"SomeEvent\"]\n1. e4 e5 2. Nf3 Nc6\n\n"
 	validateSplit(t, pgn, expectedLastLines)
 }
+
+func TestGameHooks(t *testing.T) {
+	g := NewGame()
+	var movesSeen []string
+	var endOutcome Outcome
+	var endMethod Method
+	endCalls := 0
+	g.OnMove(func(m *Move) {
+		movesSeen = append(movesSeen, m.String())
+	})
+	g.OnGameEnd(func(o Outcome, m Method) {
+		endOutcome = o
+		endMethod = m
+		endCalls++
+	})
+
+	// Fool's mate
+	for _, san := range []string{"f3", "e5", "g4", "Qh4#"} {
+		if err := g.PushMove(san, nil); err != nil {
+			t.Fatal("recieved unexpected error", err)
+		}
+	}
+	if len(movesSeen) != 4 {
+		t.Fatalf("expected 4 move callbacks but got %d", len(movesSeen))
+	}
+	if endCalls != 1 {
+		t.Fatalf("expected 1 game end callback but got %d", endCalls)
+	}
+	if endOutcome != BlackWon || endMethod != Checkmate {
+		t.Fatalf("expected black checkmate but got %s by %d", endOutcome, endMethod)
+	}
+}
+
+func TestGameHooksResign(t *testing.T) {
+	g := NewGame()
+	endCalls := 0
+	g.OnGameEnd(func(o Outcome, m Method) {
+		if o != WhiteWon || m != Resignation {
+			t.Fatalf("expected white win by resignation but got %s by %d", o, m)
+		}
+		endCalls++
+	})
+	g.Resign(Black)
+	if endCalls != 1 {
+		t.Fatalf("expected 1 game end callback but got %d", endCalls)
+	}
+}
+
+func TestGameStripAnnotations(t *testing.T) {
+	pgn := mustParsePGN("fixtures/pgns/complete_game.pgn")
+	reader := strings.NewReader(pgn)
+	pgnOpt, err := PGN(reader)
+	if err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	g := NewGame(pgnOpt)
+
+	clean := g.StripAnnotations()
+	if len(clean.Moves()) != len(g.Moves()) {
+		t.Fatalf("expected %d moves but got %d", len(g.Moves()), len(clean.Moves()))
+	}
+	for _, m := range clean.Moves() {
+		if m.Comments() != "" {
+			t.Fatalf("expected no comments but got %q", m.Comments())
+		}
+		if m.NAG() != "" {
+			t.Fatalf("expected no NAGs but got %q", m.NAG())
+		}
+		if len(m.Children()) > 1 {
+			t.Fatalf("expected no variations but got %d children", len(m.Children()))
+		}
+	}
+	out := clean.String()
+	if strings.ContainsAny(out, "{}()") {
+		t.Fatalf("expected clean movetext but got %s", out)
+	}
+	if !strings.HasSuffix(out, "0-1") {
+		t.Fatalf("expected result to be retained in %s", out)
+	}
+
+	// The original game keeps its annotations.
+	if !strings.Contains(g.String(), "{") {
+		t.Fatal("expected original game to keep its comments")
+	}
+}
+
+func TestGameResultTagSync(t *testing.T) {
+	// A game with an existing Result tag keeps it in sync with the outcome.
+	g := NewGame()
+	g.AddTagPair("Result", g.Outcome().String())
+	g.Resign(White)
+	if tag := g.GetTagPair("Result"); tag != BlackWon.String() {
+		t.Fatalf("expected Result tag %s but got %s", BlackWon, tag)
+	}
+
+	// A tag-free game does not gain a Result tag on completion.
+	g2 := NewGame()
+	for _, san := range []string{"f3", "e5", "g4", "Qh4#"} {
+		if err := g2.PushMove(san, nil); err != nil {
+			t.Fatal("recieved unexpected error", err)
+		}
+	}
+	if tag := g2.GetTagPair("Result"); tag != "" {
+		t.Fatalf("expected no Result tag but got %s", tag)
+	}
+
+	// SetResult always writes the tag.
+	g2.SetResult(BlackWon)
+	if tag := g2.GetTagPair("Result"); tag != BlackWon.String() {
+		t.Fatalf("expected Result tag %s but got %s", BlackWon, tag)
+	}
+}
+
+func TestGameResultConsistency(t *testing.T) {
+	// A checkmate with a matching result is consistent.
+	g := NewGame()
+	for _, san := range []string{"f3", "e5", "g4", "Qh4#"} {
+		if err := g.PushMove(san, nil); err != nil {
+			t.Fatal("recieved unexpected error", err)
+		}
+	}
+	if ok, detail := g.ResultConsistency(); !ok {
+		t.Fatalf("expected consistent result but got %s", detail)
+	}
+
+	// A Result tag disagreeing with the outcome is flagged.
+	g.AddTagPair("Result", "1-0")
+	if ok, _ := g.ResultConsistency(); ok {
+		t.Fatal("expected mismatched result tag to be flagged")
+	}
+
+	// A decisive result on a non-terminal board (resignation) is fine.
+	g2 := NewGame()
+	if err := g2.PushMove("e4", nil); err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	g2.Resign(Black)
+	if ok, detail := g2.ResultConsistency(); !ok {
+		t.Fatalf("expected consistent result but got %s", detail)
+	}
+
+	// A decisive result on a checkmated board must name the right winner.
+	g3 := NewGame()
+	for _, san := range []string{"f3", "e5", "g4", "Qh4#"} {
+		if err := g3.PushMove(san, nil); err != nil {
+			t.Fatal("recieved unexpected error", err)
+		}
+	}
+	g3.outcome = WhiteWon
+	if ok, _ := g3.ResultConsistency(); ok {
+		t.Fatal("expected wrong winner to be flagged")
+	}
+}
+
+func TestGameResultText(t *testing.T) {
+	tests := []struct {
+		outcome  Outcome
+		method   Method
+		expected string
+	}{
+		{NoOutcome, NoMethod, "Game in progress."},
+		{WhiteWon, Checkmate, "White wins by checkmate."},
+		{BlackWon, Checkmate, "Black wins by checkmate."},
+		{WhiteWon, Resignation, "White wins by resignation."},
+		{BlackWon, NoMethod, "Black wins."},
+		{Draw, Stalemate, "Draw by stalemate."},
+		{Draw, DrawOffer, "Draw by agreement."},
+		{Draw, ThreefoldRepetition, "Draw by threefold repetition."},
+		{Draw, FivefoldRepetition, "Draw by fivefold repetition."},
+		{Draw, FiftyMoveRule, "Draw by the fifty-move rule."},
+		{Draw, SeventyFiveMoveRule, "Draw by the seventy-five-move rule."},
+		{Draw, InsufficientMaterial, "Draw by insufficient material."},
+		{Draw, NoMethod, "Draw."},
+	}
+	for _, tt := range tests {
+		g := NewGame()
+		g.outcome = tt.outcome
+		g.method = tt.method
+		if got := g.ResultText(); got != tt.expected {
+			t.Fatalf("expected %q but got %q", tt.expected, got)
+		}
+	}
+}
+
+func TestGameCloneToCurrent(t *testing.T) {
+	g := NewGame()
+	for _, san := range []string{"e4", "e5", "Nf3", "Nc6", "Bb5", "a6"} {
+		if err := g.PushMove(san, nil); err != nil {
+			t.Fatal("recieved unexpected error", err)
+		}
+	}
+	// Add a variation to white's third move.
+	g.GoBack()
+	g.GoBack()
+	if err := g.PushMove("Bc4", nil); err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	// Walk back to black's second move and truncate there.
+	g.GoBack()
+
+	clone := g.CloneToCurrent()
+	if len(clone.Moves()) != 4 {
+		t.Fatalf("expected 4 moves but got %d", len(clone.Moves()))
+	}
+	for _, m := range clone.Moves() {
+		if len(m.Children()) > 1 {
+			t.Fatalf("expected no variations but got %d children", len(m.Children()))
+		}
+	}
+	if len(clone.currentMove.children) != 0 {
+		t.Fatal("expected no forward moves after the current move")
+	}
+	expected := "r1bqkbnr/pppp1ppp/2n5/4p3/4P3/5N2/PPPP1PPP/RNBQKB1R w KQkq - 2 3"
+	if clone.Position().String() != expected {
+		t.Fatalf("expected fen %s but got %s", expected, clone.Position().String())
+	}
+
+	// The original game is untouched.
+	if len(g.Moves()) != 6 {
+		t.Fatalf("expected original mainline of 6 moves but got %d", len(g.Moves()))
+	}
+}
+
+func TestGameReplaceCurrentMove(t *testing.T) {
+	g := NewGame()
+	for _, san := range []string{"e4", "e5", "Nf3", "Nc6"} {
+		if err := g.PushMove(san, nil); err != nil {
+			t.Fatal("recieved unexpected error", err)
+		}
+	}
+	g.GoBack()
+	g.GoBack()
+	g.currentMove.SetComment("replaced move keeps this")
+	g.currentMove.AddNAG("$1")
+
+	if err := g.ReplaceCurrentMove("c5"); err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	if g.currentMove.Comments() != "replaced move keeps this" {
+		t.Fatalf("expected comment to survive replacement but got %q", g.currentMove.Comments())
+	}
+	if g.currentMove.NAG() != "$1" {
+		t.Fatalf("expected NAG to survive replacement but got %q", g.currentMove.NAG())
+	}
+
+	moves := g.Moves()
+	if len(moves) != 4 || moves[1].String() != "c7c5" {
+		t.Fatalf("expected second move c7c5 but got %v", moves)
+	}
+	// Downstream positions are recomputed against the replacement.
+	expected := NewGame()
+	for _, san := range []string{"e4", "c5", "Nf3", "Nc6"} {
+		if err := expected.PushMove(san, nil); err != nil {
+			t.Fatal("recieved unexpected error", err)
+		}
+	}
+	if moves[3].Position().String() != expected.Position().String() {
+		t.Fatalf("expected fen %s but got %s", expected.Position().String(), moves[3].Position().String())
+	}
+
+	// Replacing with an illegal move fails.
+	if err := g.ReplaceCurrentMove("Ke2"); err == nil {
+		t.Fatal("expected error for invalid replacement")
+	}
+	// There is no move to replace at the start of the game.
+	fresh := NewGame()
+	if err := fresh.ReplaceCurrentMove("e4"); err == nil {
+		t.Fatal("expected error replacing with no current move")
+	}
+}
+
+func TestGameRecomputePositions(t *testing.T) {
+	g := NewGame()
+	for _, san := range []string{"e4", "e5", "Nf3", "Nc6", "Nc3"} {
+		if err := g.PushMove(san, nil); err != nil {
+			t.Fatal("recieved unexpected error", err)
+		}
+	}
+	// Edit the first move directly (1. e4 becomes 1. d4) and replay the
+	// subtree so every cached position reflects the edit.
+	first := g.GetRootMove().children[0]
+	first.s1 = D2
+	first.s2 = D4
+	g.RecomputePositions(first)
+
+	expected := NewGame()
+	for _, san := range []string{"d4", "e5", "Nf3", "Nc6", "Nc3"} {
+		if err := expected.PushMove(san, nil); err != nil {
+			t.Fatal("recieved unexpected error", err)
+		}
+	}
+	moves := g.Moves()
+	expectedMoves := expected.Moves()
+	for i := range moves {
+		if moves[i].Position().String() != expectedMoves[i].Position().String() {
+			t.Fatalf("expected fen %s at move %d but got %s",
+				expectedMoves[i].Position().String(), i, moves[i].Position().String())
+		}
+	}
+	// The game's current position tracks the recomputed current move.
+	if g.Position().String() != expected.Position().String() {
+		t.Fatalf("expected fen %s but got %s", expected.Position().String(), g.Position().String())
+	}
+}
+
+func TestGameApplyPV(t *testing.T) {
+	pvFromUCI := func(t *testing.T, ucis ...string) []*Move {
+		t.Helper()
+		pv := make([]*Move, 0, len(ucis))
+		for _, uci := range ucis {
+			m, err := UCINotation{}.Decode(nil, uci)
+			if err != nil {
+				t.Fatal("recieved unexpected error", err)
+			}
+			pv = append(pv, m)
+		}
+		return pv
+	}
+
+	g := NewGame()
+	if err := g.PushMove("e4", nil); err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	g.GoBack()
+
+	// A PV sharing the existing mainline extends it.
+	if bad, err := g.ApplyPV(pvFromUCI(t, "e2e4", "e7e5", "g1f3"), false); err != nil || bad != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	moves := g.Moves()
+	if len(moves) != 3 || moves[2].String() != "g1f3" {
+		t.Fatalf("expected mainline to end with g1f3 but got %v", moves)
+	}
+
+	// A divergent PV applied as a variation leaves the mainline alone.
+	g.NavigateToMainLine()
+	for g.GoBack() {
+	}
+	if bad, err := g.ApplyPV(pvFromUCI(t, "d2d4", "d7d5"), true); err != nil || bad != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	root := g.GetRootMove()
+	if len(root.children) != 2 || root.children[0].String() != "e2e4" || root.children[1].String() != "d2d4" {
+		t.Fatalf("expected variation d2d4 after mainline e2e4 but got %v", root.children)
+	}
+	if g.currentMove.String() != "d7d5" {
+		t.Fatalf("expected current move d7d5 but got %s", g.currentMove.String())
+	}
+
+	// An illegal continuation reports the offending move and keeps the
+	// legal prefix.
+	for g.GoBack() {
+	}
+	pv := pvFromUCI(t, "g1f3", "e1e2")
+	bad, err := g.ApplyPV(pv, true)
+	if err == nil {
+		t.Fatal("expected error for illegal pv move")
+	}
+	if bad != pv[1] {
+		t.Fatalf("expected illegal move e1e2 but got %v", bad)
+	}
+	if g.currentMove.String() != "g1f3" {
+		t.Fatalf("expected current move g1f3 but got %s", g.currentMove.String())
+	}
+}
+
+func TestGamePushMoves(t *testing.T) {
+	g := NewGame()
+	if err := g.PushMoves([]string{"e4", "e5", "Nf3", "Nc6"}, nil); err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	moves := g.Moves()
+	if len(moves) != 4 || moves[3].String() != "b8c6" {
+		t.Fatalf("expected 4 moves ending with b8c6 but got %v", moves)
+	}
+}
+
+func TestGamePushMovesRollback(t *testing.T) {
+	g := NewGame()
+	if err := g.PushMoves([]string{"e4", "e5"}, nil); err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	fen := g.FEN()
+	current := g.currentMove
+
+	// The third move is illegal; nothing may stick.
+	err := g.PushMoves([]string{"Nf3", "Nc6", "Qd5"}, nil)
+	if err == nil {
+		t.Fatal("expected error for illegal move")
+	}
+	if g.currentMove != current {
+		t.Fatal("expected current move to be restored")
+	}
+	if g.FEN() != fen {
+		t.Fatalf("expected fen %s but got %s", fen, g.FEN())
+	}
+	if len(g.Moves()) != 2 {
+		t.Fatalf("expected 2 moves but got %d", len(g.Moves()))
+	}
+	if len(current.children) != 0 {
+		t.Fatalf("expected no children after rollback but got %d", len(current.children))
+	}
+}
+
+func TestGameShortID(t *testing.T) {
+	g := NewGame()
+	if err := g.PushMoves([]string{"e4", "e5", "Nf3", "Nc6"}, nil); err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	id := g.ShortID()
+	if len(id) != 8 {
+		t.Fatalf("expected 8 character id but got %q", id)
+	}
+
+	// Clones and PGN round trips keep the same id.
+	if clone := g.Clone(); clone.ShortID() != id {
+		t.Fatalf("expected clone id %s but got %s", id, clone.ShortID())
+	}
+	reparsed := NewGame()
+	if err := reparsed.UnmarshalText([]byte(g.String())); err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	if reparsed.ShortID() != id {
+		t.Fatalf("expected round trip id %s but got %s", id, reparsed.ShortID())
+	}
+
+	// Distinct games get distinct ids.
+	other := NewGame()
+	if err := other.PushMoves([]string{"d4", "d5"}, nil); err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	if other.ShortID() == id {
+		t.Fatal("expected distinct games to have distinct ids")
+	}
+	if NewGame().ShortID() == id {
+		t.Fatal("expected empty game to have a distinct id")
+	}
+}
+
+func TestGameStudyTags(t *testing.T) {
+	file, err := os.Open(filepath.Join("fixtures/pgns", "lichess_study.pgn"))
+	if err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	defer file.Close()
+
+	scanner := NewScanner(file)
+	g, err := scanner.ParseNext()
+	if err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	if g.Orientation() != Black {
+		t.Fatalf("expected black orientation but got %s", g.Orientation().Name())
+	}
+	if !g.IsGamebook() {
+		t.Fatal("expected gamebook chapter")
+	}
+	if len(g.Moves()) != 3 {
+		t.Fatalf("expected 3 moves but got %d", len(g.Moves()))
+	}
+
+	// The tags survive a serialization round trip.
+	reparsed := NewGame()
+	if err := reparsed.UnmarshalText([]byte(g.String())); err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	if reparsed.Orientation() != Black || !reparsed.IsGamebook() {
+		t.Fatal("expected study tags to survive round trip")
+	}
+
+	// Games without the tags use the defaults.
+	plain := NewGame()
+	if plain.Orientation() != White || plain.IsGamebook() {
+		t.Fatal("expected default orientation white and no gamebook mode")
+	}
+}
+
+func TestGameCurrentMoveDisplay(t *testing.T) {
+	g := NewGame()
+	if number, isBlack := g.CurrentMoveDisplay(); number != 0 || isBlack {
+		t.Fatalf("expected 0 false at start but got %d %t", number, isBlack)
+	}
+
+	expectations := []struct {
+		san     string
+		number  int
+		isBlack bool
+	}{
+		{"e4", 1, false},
+		{"e5", 1, true},
+		{"Nf3", 2, false},
+		{"Nc6", 2, true},
+	}
+	for _, tt := range expectations {
+		if err := g.PushMove(tt.san, nil); err != nil {
+			t.Fatal("recieved unexpected error", err)
+		}
+		if number, isBlack := g.CurrentMoveDisplay(); number != tt.number || isBlack != tt.isBlack {
+			t.Fatalf("expected %d %t after %s but got %d %t", tt.number, tt.isBlack, tt.san, number, isBlack)
+		}
+	}
+
+	// A variation on Black's first move still displays as "1...".
+	g.GoBack()
+	g.GoBack()
+	g.GoBack()
+	if err := g.PushMove("c5", nil); err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	if number, isBlack := g.CurrentMoveDisplay(); number != 1 || !isBlack {
+		t.Fatalf("expected 1 true in variation but got %d %t", number, isBlack)
+	}
+
+	// Games set up from a mid-game FEN start at the right number.
+	fen, err := FEN("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR b KQkq - 0 17")
+	if err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	fromPos := NewGame(fen)
+	if err := fromPos.PushMove("e5", nil); err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	if number, isBlack := fromPos.CurrentMoveDisplay(); number != 17 || !isBlack {
+		t.Fatalf("expected 17 true but got %d %t", number, isBlack)
+	}
+}
+
+func TestGameDrawOffers(t *testing.T) {
+	g := NewGame()
+	if !g.CanOfferDraw(White) {
+		t.Fatal("expected white to be able to offer a draw")
+	}
+	if err := g.OfferDraw(White); err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	// An immediate re-offer is rejected.
+	if g.CanOfferDraw(White) {
+		t.Fatal("expected immediate re-offer to be rejected")
+	}
+	if err := g.OfferDraw(White); err == nil {
+		t.Fatal("expected error for immediate re-offer")
+	}
+	// Black may still offer independently.
+	if !g.CanOfferDraw(Black) {
+		t.Fatal("expected black to be able to offer a draw")
+	}
+
+	// White moving does not reset the cooldown; the opponent must move.
+	if err := g.PushMove("e4", nil); err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	if g.CanOfferDraw(White) {
+		t.Fatal("expected re-offer to stay rejected until black moves")
+	}
+	if err := g.PushMove("e5", nil); err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	if !g.CanOfferDraw(White) {
+		t.Fatal("expected re-offer to be allowed after black moved")
+	}
+
+	// Accepting an offer ends the game and blocks further offers.
+	if err := g.Draw(DrawOffer); err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	if g.CanOfferDraw(White) || g.CanOfferDraw(Black) {
+		t.Fatal("expected no draw offers after the game ended")
+	}
+	if g.CanOfferDraw(NoColor) {
+		t.Fatal("expected no draw offers for NoColor")
+	}
+}
+
+func TestGameDrawOfferCooldown(t *testing.T) {
+	g := NewGame()
+	g.SetDrawOfferCooldown(2)
+	if err := g.OfferDraw(White); err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	for _, san := range []string{"e4", "e5"} {
+		if err := g.PushMove(san, nil); err != nil {
+			t.Fatal("recieved unexpected error", err)
+		}
+	}
+	// Only one black move so far; two are required.
+	if g.CanOfferDraw(White) {
+		t.Fatal("expected re-offer to be rejected after one opponent move")
+	}
+	for _, san := range []string{"Nf3", "Nc6"} {
+		if err := g.PushMove(san, nil); err != nil {
+			t.Fatal("recieved unexpected error", err)
+		}
+	}
+	if !g.CanOfferDraw(White) {
+		t.Fatal("expected re-offer to be allowed after two opponent moves")
+	}
+}
+
+func TestGameSetPosition(t *testing.T) {
+	g := NewGame()
+	for _, san := range []string{"e4", "e5", "Nf3"} {
+		if err := g.PushMove(san, nil); err != nil {
+			t.Fatal("recieved unexpected error", err)
+		}
+	}
+
+	fenStr := "8/8/8/8/8/4k3/4p3/4K3 w - - 0 1"
+	pos, err := decodeFEN(fenStr)
+	if err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	if err := g.SetPosition(pos); err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+
+	// Prior history is discarded and the new position becomes the root.
+	if len(g.Moves()) != 0 {
+		t.Fatalf("expected no moves but got %d", len(g.Moves()))
+	}
+	if g.FEN() != fenStr {
+		t.Fatalf("expected fen %s but got %s", fenStr, g.FEN())
+	}
+	if g.GetTagPair("SetUp") != "1" || g.GetTagPair("FEN") != fenStr {
+		t.Fatal("expected SetUp and FEN tag pairs to record the position")
+	}
+	// The position is stalemate, so the status is re-evaluated.
+	if g.Outcome() != Draw || g.Method() != Stalemate {
+		t.Fatalf("expected stalemate draw but got %s by %s", g.Outcome(), g.Method())
+	}
+
+	// Play can continue from a non-terminal position.
+	g = NewGame()
+	pos, err = decodeFEN("4k3/8/8/8/8/8/4P3/4K3 w - - 0 1")
+	if err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	if err := g.SetPosition(pos); err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	if err := g.PushMove("e4", nil); err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	if g.Outcome() != NoOutcome {
+		t.Fatalf("expected outcome %s but got %s", NoOutcome, g.Outcome())
+	}
+
+	if err := g.SetPosition(nil); err == nil {
+		t.Fatal("expected error for nil position")
+	}
+}
+
+func TestGameMateSequence(t *testing.T) {
+	pgn := `[Event "?"]
+
+1. f3 { [%eval -0.8] } e5 { [%eval -1.0] } 2. g4 { [%eval #-1] } Qh4# 0-1`
+	scanner := NewScanner(strings.NewReader(pgn))
+	g, err := scanner.ParseNext()
+	if err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+
+	// From the start no mate is announced yet.
+	g.NavigateToMainLine()
+	for g.GoBack() {
+	}
+	if seq, ok := g.MateSequence(); ok || seq != nil {
+		t.Fatal("expected no mate sequence from the starting position")
+	}
+
+	// After 1...e5 the eval announces mate in one for black.
+	g.GoForward()
+	g.GoForward()
+	seq, ok := g.MateSequence()
+	if !ok {
+		t.Fatal("expected a mate sequence after 1...e5")
+	}
+	if len(seq) != 2 || seq[0].String() != "g2g4" || seq[1].String() != "d8h4" {
+		t.Fatalf("expected sequence g2g4 d8h4 but got %v", seq)
+	}
+
+	// A mate announcement that fizzles out is not a sequence.
+	pgn = `[Event "?"]
+
+1. f3 { [%eval -0.8] } e5 { [%eval -1.0] } 2. g4 { [%eval #-1] } Nc6 { [%eval 0.5] } *`
+	scanner = NewScanner(strings.NewReader(pgn))
+	g, err = scanner.ParseNext()
+	if err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	for g.GoBack() {
+	}
+	g.GoForward()
+	g.GoForward()
+	if _, ok := g.MateSequence(); ok {
+		t.Fatal("expected no mate sequence when mate is not delivered")
+	}
+}
+
+func TestMoveEval(t *testing.T) {
+	m := &Move{}
+	if _, _, ok := m.Eval(); ok {
+		t.Fatal("expected no eval for move without an eval command")
+	}
+	m.SetCommand("eval", "0.17")
+	if pawns, mate, ok := m.Eval(); !ok || pawns != 0.17 || mate != 0 {
+		t.Fatalf("expected pawn eval 0.17 but got %v %d %t", pawns, mate, ok)
+	}
+	m.SetCommand("eval", "#3")
+	if pawns, mate, ok := m.Eval(); !ok || pawns != 0 || mate != 3 {
+		t.Fatalf("expected mate in 3 but got %v %d %t", pawns, mate, ok)
+	}
+	m.SetCommand("eval", "#-2")
+	if _, mate, ok := m.Eval(); !ok || mate != -2 {
+		t.Fatalf("expected mate in -2 but got %d %t", mate, ok)
+	}
+	m.SetCommand("eval", "mate")
+	if _, _, ok := m.Eval(); ok {
+		t.Fatal("expected no eval for malformed value")
+	}
+}
+
+func TestRepetitionCountsIncremental(t *testing.T) {
+	bruteForce := func(g *Game) int {
+		count := 0
+		for _, pos := range g.Positions() {
+			if g.pos.samePosition(pos) {
+				count++
+			}
+		}
+		return count
+	}
+
+	g := NewGame()
+	moves := []string{
+		"Nf3", "Nf6", "Ng1", "Ng8",
+		"Nf3", "Nf6", "Ng1", "Ng8",
+		"Nf3", "Nf6",
+	}
+	for _, san := range moves {
+		if err := g.PushMove(san, nil); err != nil {
+			t.Fatal("recieved unexpected error", err)
+		}
+		if got, want := g.numOfRepetitions(), bruteForce(g); got != want {
+			t.Fatalf("after %s expected %d repetitions but got %d", san, want, got)
+		}
+	}
+	if g.numOfRepetitions() != 3 {
+		t.Fatalf("expected 3 repetitions but got %d", g.numOfRepetitions())
+	}
+
+	// Navigation and variation pushes keep the counts in sync too.
+	g.GoBack()
+	g.GoBack()
+	if got, want := g.numOfRepetitions(), bruteForce(g); got != want {
+		t.Fatalf("after GoBack expected %d repetitions but got %d", want, got)
+	}
+	if err := g.PushMove("e4", nil); err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	if got, want := g.numOfRepetitions(), bruteForce(g); got != want {
+		t.Fatalf("after variation expected %d repetitions but got %d", want, got)
+	}
+	// Promoting the variation to the main line rebuilds the counts.
+	g.GoBack()
+	if err := g.PushMove("e4", &PushMoveOptions{ForceMainline: true}); err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	if got, want := g.numOfRepetitions(), bruteForce(g); got != want {
+		t.Fatalf("after reorder expected %d repetitions but got %d", want, got)
+	}
+}
+
+func BenchmarkRepetitionPush(b *testing.B) {
+	g := NewGame()
+	g.ignoreAutomaticDraws = true
+	moves := []string{"Nf3", "Nf6", "Ng1", "Ng8"}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		if err := g.PushMove(moves[n%len(moves)], nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestGameMoveList(t *testing.T) {
+	pgn := `[Event "?"]
+
+1. e4 { King's pawn. } e5 2. Nf3 $1 Nc6 *`
+	scanner := NewScanner(strings.NewReader(pgn))
+	g, err := scanner.ParseNext()
+	if err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+
+	views := g.MoveList()
+	if len(views) != 4 {
+		t.Fatalf("expected 4 moves but got %d", len(views))
+	}
+
+	first := views[0]
+	if first.SAN != "e4" || first.UCI != "e2e4" {
+		t.Fatalf("expected e4/e2e4 but got %s/%s", first.SAN, first.UCI)
+	}
+	if first.Ply != 1 || first.Number != 1 || first.IsBlack {
+		t.Fatalf("expected ply 1 move 1 white but got %d %d %t", first.Ply, first.Number, first.IsBlack)
+	}
+	if first.Comment != "King's pawn." {
+		t.Fatalf("expected comment but got %q", first.Comment)
+	}
+	if first.FEN != "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1" {
+		t.Fatalf("unexpected fen %s", first.FEN)
+	}
+
+	third := views[2]
+	if third.SAN != "Nf3" || third.Ply != 3 || third.Number != 2 || third.IsBlack {
+		t.Fatalf("unexpected view for Nf3: %+v", third)
+	}
+	if len(third.NAGs) != 1 || third.NAGs[0] != "$1" {
+		t.Fatalf("expected NAG $1 but got %v", third.NAGs)
+	}
+
+	fourth := views[3]
+	if fourth.SAN != "Nc6" || fourth.Ply != 4 || fourth.Number != 2 || !fourth.IsBlack {
+		t.Fatalf("unexpected view for Nc6: %+v", fourth)
+	}
+
+	if views := NewGame().MoveList(); len(views) != 0 {
+		t.Fatalf("expected no views for a new game but got %d", len(views))
+	}
+}
+
+func TestGameWriteMoveTable(t *testing.T) {
+	file, err := os.Open(filepath.Join("fixtures/pgns", "complete_game.pgn"))
+	if err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	defer file.Close()
+
+	g, err := NewScanner(file).ParseNext()
+	if err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+
+	var tsv strings.Builder
+	if err := g.WriteMoveTable(&tsv, TSVFormat); err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	lines := strings.Split(strings.TrimRight(tsv.String(), "\n"), "\n")
+	if len(lines) != len(g.Moves())+1 {
+		t.Fatalf("expected %d rows but got %d", len(g.Moves())+1, len(lines))
+	}
+	if lines[0] != "ply\tfen\tmove_uci\tmove_san\teval\tclk" {
+		t.Fatalf("unexpected header %q", lines[0])
+	}
+	first := strings.Split(lines[1], "\t")
+	if first[0] != "1" || first[1] != StartingPosition().String() {
+		t.Fatalf("unexpected first row %q", lines[1])
+	}
+	if first[2] != "d2d4" || first[3] != "d4" {
+		t.Fatalf("unexpected first move columns %q", lines[1])
+	}
+	// The fixture stores lichess clocks on every move.
+	if first[5] == "" {
+		t.Fatal("expected a clk value in the first row")
+	}
+
+	var csvOut strings.Builder
+	if err := g.WriteMoveTable(&csvOut, CSVFormat); err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	csvLines := strings.Split(strings.TrimRight(csvOut.String(), "\n"), "\n")
+	if len(csvLines) != len(lines) {
+		t.Fatalf("expected %d csv rows but got %d", len(lines), len(csvLines))
+	}
+	if csvLines[0] != "ply,fen,move_uci,move_san,eval,clk" {
+		t.Fatalf("unexpected csv header %q", csvLines[0])
+	}
+
+	if err := g.WriteMoveTable(&csvOut, TableFormat(99)); err == nil {
+		t.Fatal("expected error for invalid format")
+	}
+}
+
+func TestGameCommentsByMove(t *testing.T) {
+	pgn := `[Event "?"]
+
+1. e4 {king pawn} e5 (1... c5 {sicilian} 2. Nf3) 2. Nf3 {develops} *`
+	scanner := NewScanner(strings.NewReader(pgn))
+	g, err := scanner.ParseNext()
+	if err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	byMove := g.CommentsByMove()
+	if len(byMove) != 2 {
+		t.Fatalf("expected 2 commented moves but got %d", len(byMove))
+	}
+	moves := g.Moves()
+	if got := byMove[moves[0]]; !strings.Contains(got, "king pawn") {
+		t.Fatalf("expected comment on e4 but got %q", got)
+	}
+	if got := byMove[moves[2]]; !strings.Contains(got, "develops") {
+		t.Fatalf("expected comment on Nf3 but got %q", got)
+	}
+	if _, ok := byMove[moves[1]]; ok {
+		t.Fatal("expected no comment on e5")
+	}
+}
+
+func TestGameOpeningSignature(t *testing.T) {
+	g := NewGame()
+	if got := g.OpeningSignature(0); got != "" {
+		t.Fatalf("expected empty signature but got %q", got)
+	}
+	for _, san := range []string{"e4", "e5", "Nf3", "Nc6"} {
+		if err := g.PushMove(san, nil); err != nil {
+			t.Fatal("recieved unexpected error", err)
+		}
+	}
+	if got := g.OpeningSignature(3); got != "e2e4 e7e5 g1f3" {
+		t.Fatalf("expected signature %q but got %q", "e2e4 e7e5 g1f3", got)
+	}
+	// Shorter games yield the moves that were played.
+	if got := g.OpeningSignature(0); got != "e2e4 e7e5 g1f3 b8c6" {
+		t.Fatalf("expected full signature but got %q", got)
+	}
+
+	// Two games sharing an opening share the signature.
+	other := NewGame()
+	for _, san := range []string{"e4", "e5", "Nf3", "Nf6"} {
+		if err := other.PushMove(san, nil); err != nil {
+			t.Fatal("recieved unexpected error", err)
+		}
+	}
+	if g.OpeningSignature(3) != other.OpeningSignature(3) {
+		t.Fatal("expected matching signatures for a shared opening")
+	}
+	if g.OpeningSignature(4) == other.OpeningSignature(4) {
+		t.Fatal("expected diverging signatures after the openings split")
+	}
+}
+
+func TestGameStepN(t *testing.T) {
+	g := NewGame()
+	sans := []string{"e4", "e5", "Nf3", "Nc6", "Bb5", "a6"}
+	for _, san := range sans {
+		if err := g.PushMove(san, nil); err != nil {
+			t.Fatal("recieved unexpected error", err)
+		}
+	}
+	moves := g.Moves()
+
+	back := g.StepBackwardN(3)
+	if len(back) != 3 {
+		t.Fatalf("expected 3 moves stepped back but got %d", len(back))
+	}
+	if back[0] != moves[5] || back[1] != moves[4] || back[2] != moves[3] {
+		t.Fatal("expected the last three moves in reverse order")
+	}
+	if g.CurrentPosition().String() != moves[2].position.String() {
+		t.Fatal("expected the pointer after the third move")
+	}
+
+	forward := g.StepForwardN(2)
+	if len(forward) != 2 {
+		t.Fatalf("expected 2 moves stepped forward but got %d", len(forward))
+	}
+	if forward[0] != moves[3] || forward[1] != moves[4] {
+		t.Fatal("expected the fourth and fifth moves in order")
+	}
+
+	// Stepping past the ends stops at the boundaries.
+	if got := g.StepForwardN(5); len(got) != 1 {
+		t.Fatalf("expected 1 remaining move forward but got %d", len(got))
+	}
+	if got := g.StepBackwardN(10); len(got) != 6 {
+		t.Fatalf("expected 6 moves back to the root but got %d", len(got))
+	}
+	if !g.IsAtStart() {
+		t.Fatal("expected the game at the start")
+	}
+}
+
+func TestGameFlipped(t *testing.T) {
+	g := NewGame()
+	for _, san := range []string{"e4", "e5", "Nf3", "Nc6", "Bb5"} {
+		if err := g.PushMove(san, nil); err != nil {
+			t.Fatal("recieved unexpected error", err)
+		}
+	}
+	g.AddTagPair("White", "Alice")
+	g.AddTagPair("Black", "Bob")
+	g.AddTagPair("Result", "*")
+	g.Resign(Black)
+
+	flipped, err := g.Flipped()
+	if err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	if flipped.Outcome() != BlackWon {
+		t.Fatalf("expected inverted outcome 0-1 but got %s", flipped.Outcome())
+	}
+	if flipped.GetTagPair("White") != "Bob" || flipped.GetTagPair("Black") != "Alice" {
+		t.Fatal("expected swapped player tags")
+	}
+
+	// Moves map to their mirrored counterparts.
+	moves := flipped.Moves()
+	if len(moves) != 5 {
+		t.Fatalf("expected 5 moves but got %d", len(moves))
+	}
+	if moves[0].S1() != E7 || moves[0].S2() != E5 {
+		t.Fatalf("expected e7e5 but got %s", moves[0].String())
+	}
+	if moves[4].S1() != F8 || moves[4].S2() != B4 {
+		t.Fatalf("expected f8b4 but got %s", moves[4].String())
+	}
+	// The final position is the mirror of the original's.
+	want, err := g.CurrentPosition().Transform(VerticalFlip)
+	if err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	if flipped.CurrentPosition().String() != want.String() {
+		t.Fatalf("expected fen %s but got %s", want.String(), flipped.CurrentPosition().String())
+	}
+
+	// Flipping twice restores the original positions and result.
+	restored, err := flipped.Flipped()
+	if err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	if restored.CurrentPosition().String() != g.CurrentPosition().String() {
+		t.Fatalf("expected fen %s but got %s",
+			g.CurrentPosition().String(), restored.CurrentPosition().String())
+	}
+	if restored.Outcome() != g.Outcome() {
+		t.Fatalf("expected outcome %s but got %s", g.Outcome(), restored.Outcome())
+	}
+}
+
+func TestGameAnnotationTags(t *testing.T) {
+	pgn := `[Event "?"]
+[Annotator "Tarrasch"]
+[Opening "Ruy Lopez"]
+[ECO "C60"]
+
+1. e4 e5 2. Nf3 Nc6 3. Bb5 *
+`
+	pgnOpt, err := PGN(strings.NewReader(pgn))
+	if err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	g := NewGame(pgnOpt)
+	if v := g.Annotator(); v != "Tarrasch" {
+		t.Fatalf("expected annotator Tarrasch but got %q", v)
+	}
+	if v := g.Opening(); v != "Ruy Lopez" {
+		t.Fatalf("expected opening Ruy Lopez but got %q", v)
+	}
+	if v := g.ECO(); v != "C60" {
+		t.Fatalf("expected ECO C60 but got %q", v)
+	}
+
+	// The tags survive a serialization round trip.
+	pgnOpt, err = PGN(strings.NewReader(g.String()))
+	if err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	round := NewGame(pgnOpt)
+	if round.Annotator() != "Tarrasch" || round.Opening() != "Ruy Lopez" || round.ECO() != "C60" {
+		t.Fatalf("expected annotation tags to round-trip but got %v", round.tagPairs)
+	}
+
+	// Absent tags read as empty strings.
+	if v := NewGame().Annotator(); v != "" {
+		t.Fatalf("expected empty annotator but got %q", v)
+	}
+}
+
+func TestGameEloAccessors(t *testing.T) {
+	g := NewGame()
+	if _, ok := g.WhiteElo(); ok {
+		t.Fatal("expected no white elo without a tag")
+	}
+
+	g.AddTagPair("WhiteElo", "2450")
+	g.AddTagPair("BlackElo", "2350?")
+	if elo, ok := g.WhiteElo(); !ok || elo != 2450 {
+		t.Fatalf("expected white elo 2450 but got %d %v", elo, ok)
+	}
+	// A provisional marker still parses.
+	if elo, ok := g.BlackElo(); !ok || elo != 2350 {
+		t.Fatalf("expected black elo 2350 but got %d %v", elo, ok)
+	}
+	if avg, ok := g.AverageElo(); !ok || avg != 2400 {
+		t.Fatalf("expected average elo 2400 but got %d %v", avg, ok)
+	}
+
+	// Unknown ratings read as absent and poison the average.
+	g.AddTagPair("BlackElo", "?")
+	if _, ok := g.BlackElo(); ok {
+		t.Fatal("expected no black elo for unknown rating")
+	}
+	if _, ok := g.AverageElo(); ok {
+		t.Fatal("expected no average elo with one rating unknown")
+	}
+}
+
+func TestGameForEachPosition(t *testing.T) {
+	pgnData := mustParsePGN("fixtures/pgns/variations.pgn")
+	pgnOpt, err := PGN(strings.NewReader(pgnData))
+	if err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	g := NewGame(pgnOpt)
+
+	count := 0
+	g.ForEachPosition(func(m *Move, pos *Position) {
+		if m == nil || pos == nil {
+			t.Fatal("expected move and position for every node")
+		}
+		if pos != m.Position() {
+			t.Fatal("expected the move's cached position")
+		}
+		count++
+	})
+
+	// 7 mainline moves plus 16 across the four variations.
+	if count != 23 {
+		t.Fatalf("expected 23 visited positions but got %d", count)
+	}
+	if mainline := len(g.Moves()); count <= mainline {
+		t.Fatalf("expected variations beyond the %d mainline moves", mainline)
+	}
+
+	// A nil callback is a no-op.
+	g.ForEachPosition(nil)
+}
+
+func TestGameMoveLogLine(t *testing.T) {
+	g := NewGame()
+	for _, san := range []string{"e4", "e5"} {
+		if err := g.PushMove(san, nil); err != nil {
+			t.Fatal("recieved unexpected error", err)
+		}
+	}
+	moves := g.Moves()
+
+	// A clock command renders in parentheses.
+	moves[0].SetCommand("clk", "0:01:23")
+	if s := g.MoveLogLine(moves[0]); s != "1. e4 (0:01:23)" {
+		t.Fatalf("expected 1. e4 (0:01:23) but got %q", s)
+	}
+
+	// Black moves use the ellipsis form; %emt is the fallback.
+	moves[1].SetElapsedTime(5 * time.Second)
+	if s := g.MoveLogLine(moves[1]); s != "1... e5 (0:00:05)" {
+		t.Fatalf("expected 1... e5 (0:00:05) but got %q", s)
+	}
+
+	// No recorded time drops the parenthetical.
+	moves[1].command = nil
+	if s := g.MoveLogLine(moves[1]); s != "1... e5" {
+		t.Fatalf("expected 1... e5 but got %q", s)
+	}
+
+	if s := g.MoveLogLine(nil); s != "" {
+		t.Fatalf("expected empty string for nil move but got %q", s)
+	}
+}
+
+func TestCheckmateOnSeventyFifthMove(t *testing.T) {
+	// The mating move is also the 150th half-move without a pawn move
+	// or capture; mate takes precedence over the automatic draw.
+	fen, _ := FEN("7k/5K2/8/8/8/8/8/6R1 w - - 149 100")
+	g := NewGame(fen)
+	if err := g.PushMove("Rh1", nil); err != nil {
+		t.Fatal(err)
+	}
+	if g.Outcome() != WhiteWon || g.Method() != Checkmate {
+		t.Fatalf("expected checkmate win but got %s by %s", g.Outcome(), g.Method())
+	}
+}
+
+func TestCheckmateRepetitionPrecedence(t *testing.T) {
+	// A mating position can never itself be a fivefold repetition (its
+	// earlier occurrences would already have ended the game), so shuffle
+	// to four occurrences of the starting position and verify the mate
+	// still wins rather than feeding the repetition draw.
+	fen, _ := FEN("7k/5K2/8/8/8/8/8/1N4R1 w - - 0 1")
+	g := NewGame(fen)
+	moves := []string{
+		"Nc3", "Kh7", "Nb1", "Kh8",
+		"Nc3", "Kh7", "Nb1", "Kh8",
+		"Nc3", "Kh7", "Nb1", "Kh8",
+	}
+	for _, m := range moves {
+		if err := g.PushMove(m, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if g.Outcome() != NoOutcome {
+		t.Fatalf("expected game in progress but got %s by %s", g.Outcome(), g.Method())
+	}
+	if err := g.PushMove("Rh1", nil); err != nil {
+		t.Fatal(err)
+	}
+	if g.Outcome() != WhiteWon || g.Method() != Checkmate {
+		t.Fatalf("expected checkmate win but got %s by %s", g.Outcome(), g.Method())
+	}
+}
+
+func TestGameRoundNumber(t *testing.T) {
+	g := NewGame()
+	if _, _, ok := g.RoundNumber(); ok {
+		t.Fatal("expected no round without a tag")
+	}
+
+	g.AddTagPair("Round", "3")
+	if round, board, ok := g.RoundNumber(); !ok || round != 3 || board != 0 {
+		t.Fatalf("expected round 3 board 0 but got %d.%d %v", round, board, ok)
+	}
+
+	// The dotted team-match form carries the board number.
+	g.AddTagPair("Round", "3.2")
+	if round, board, ok := g.RoundNumber(); !ok || round != 3 || board != 2 {
+		t.Fatalf("expected round 3 board 2 but got %d.%d %v", round, board, ok)
+	}
+
+	for _, unknown := range []string{"-", "?", "semifinal"} {
+		g.AddTagPair("Round", unknown)
+		if _, _, ok := g.RoundNumber(); ok {
+			t.Fatalf("expected no round for %q", unknown)
+		}
+	}
+}
+
+func TestGameMaterializePositions(t *testing.T) {
+	// A from-position game with a variation grafted on without a
+	// cached position.
+	fen, err := FEN("rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq - 0 1")
+	if err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	g := NewGame(fen)
+	if err := g.PushMove("e5", nil); err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	variation := &Move{s1: C7, s2: C5}
+	g.AddVariation(g.GetRootMove(), variation)
+	if variation.Position() != nil {
+		t.Fatal("expected grafted move to start without a position")
+	}
+
+	g.MaterializePositions()
+	if variation.Position() == nil {
+		t.Fatal("expected a materialized position")
+	}
+	expected := unsafeFEN("rnbqkbnr/pp1ppppp/8/2p5/4P3/8/PPPP1PPP/RNBQKBNR w KQkq c6 0 2")
+	if variation.Position().MinimalFEN() != expected.MinimalFEN() {
+		t.Fatalf("expected %s but got %s", expected.MinimalFEN(), variation.Position().MinimalFEN())
+	}
+
+	// Idempotent: a second pass leaves cached positions alone.
+	cached := variation.Position()
+	mainline := g.Moves()[0].Position()
+	g.MaterializePositions()
+	if variation.Position() != cached || g.Moves()[0].Position() != mainline {
+		t.Fatal("expected cached positions to be left untouched")
+	}
+}
+
+func TestGamePlyCountTag(t *testing.T) {
+	g := NewGame()
+	for _, san := range []string{"e4", "e5", "Nf3"} {
+		if err := g.PushMove(san, nil); err != nil {
+			t.Fatal("recieved unexpected error", err)
+		}
+	}
+	if _, ok := g.DeclaredPlyCount(); ok {
+		t.Fatal("expected no declared ply count without a tag")
+	}
+	if ok, _ := g.PlyCountConsistency(); !ok {
+		t.Fatal("expected games without the tag to be consistent")
+	}
+
+	g.SetPlyCountTag()
+	if count, ok := g.DeclaredPlyCount(); !ok || count != 3 {
+		t.Fatalf("expected declared ply count 3 but got %d %v", count, ok)
+	}
+	if ok, _ := g.PlyCountConsistency(); !ok {
+		t.Fatal("expected an accurate tag to be consistent")
+	}
+	if !strings.Contains(g.String(), `[PlyCount "3"]`) {
+		t.Fatalf("expected PlyCount tag in %s", g.String())
+	}
+
+	// A stale declared count flags the mismatch.
+	g.AddTagPair("PlyCount", "40")
+	if ok, reason := g.PlyCountConsistency(); ok || reason == "" {
+		t.Fatal("expected a mismatched ply count to be flagged")
+	}
+}
+
+func TestGameReplay(t *testing.T) {
+	g := NewGame()
+	for _, san := range []string{"e4", "e5", "Nf3", "Nc6"} {
+		if err := g.PushMove(san, nil); err != nil {
+			t.Fatal("recieved unexpected error", err)
+		}
+	}
+
+	var plies []int
+	err := g.Replay(func(pos *Position, m *Move, ply int) error {
+		if pos == nil || pos != m.Position() {
+			t.Fatal("expected the position after the move")
+		}
+		plies = append(plies, ply)
+		return nil
+	})
+	if err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	if len(plies) != 4 || plies[0] != 1 || plies[3] != 4 {
+		t.Fatalf("expected plies 1 through 4 but got %v", plies)
+	}
+
+	// The callback can abort the replay.
+	stop := errors.New("stop")
+	count := 0
+	err = g.Replay(func(_ *Position, _ *Move, ply int) error {
+		count++
+		if ply == 2 {
+			return stop
+		}
+		return nil
+	})
+	if !errors.Is(err, stop) || count != 2 {
+		t.Fatalf("expected replay to stop at ply 2 but got %v after %d calls", err, count)
+	}
+
+	// From-position games replay from the root position.
+	fen, _ := FEN("rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq - 0 1")
+	fromPos := NewGame(fen)
+	if err := fromPos.PushMove("e5", nil); err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	err = fromPos.Replay(func(pos *Position, m *Move, ply int) error {
+		if ply != 1 || pos.Turn() != White {
+			t.Fatalf("expected black's reply as ply 1 but got ply %d", ply)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+}
+
+func TestGameNonDrawingMoves(t *testing.T) {
+	// One knight retreat short of the fivefold repetition draw.
+	g := NewGame()
+	moves := []string{
+		"Nf3", "Nf6", "Ng1", "Ng8",
+		"Nf3", "Nf6", "Ng1", "Ng8",
+		"Nf3", "Nf6", "Ng1", "Ng8",
+		"Nf3", "Nf6", "Ng1",
+	}
+	for _, m := range moves {
+		if err := g.PushMove(m, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if g.Outcome() != NoOutcome {
+		t.Fatalf("expected game in progress but got %s", g.Outcome())
+	}
+
+	valid := g.ValidMoves()
+	nonDrawing := g.NonDrawingMoves()
+	if len(nonDrawing) != len(valid)-1 {
+		t.Fatalf("expected one drawing move filtered from %d but got %d", len(valid), len(nonDrawing))
+	}
+	for _, m := range nonDrawing {
+		if m.S1() == F6 && m.S2() == G8 {
+			t.Fatal("expected the repeating knight retreat to be excluded")
+		}
+	}
+
+	// A move crossing the 75-move threshold is excluded too.
+	fen, _ := FEN("2r3k1/1q1nbppp/r3p3/3pP3/pPpP4/P1Q2N2/2RN1PPP/2R4K b - b3 149 80")
+	clock := NewGame(fen)
+	for _, m := range clock.NonDrawingMoves() {
+		if !m.HasTag(Capture) && clock.Position().Board().Piece(m.S1()).Type() != Pawn {
+			t.Fatalf("expected only clock-resetting moves but got %s", m.String())
+		}
+	}
+}
+
+func TestGameTagValueEscaping(t *testing.T) {
+	g := NewGame()
+	g.AddTagPair("Event", `The "Unofficial" C:\chess Open`)
+
+	out := g.String()
+	if !strings.Contains(out, `[Event "The \"Unofficial\" C:\\chess Open"]`) {
+		t.Fatalf("expected escaped tag value in %s", out)
+	}
+
+	// The value unescapes to the original on re-parse.
+	pgnOpt, err := PGN(strings.NewReader(out))
+	if err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	round := NewGame(pgnOpt)
+	if v := round.GetTagPair("Event"); v != `The "Unofficial" C:\chess Open` {
+		t.Fatalf("expected tag value to round-trip but got %q", v)
+	}
+}
+
+func TestThreeCheckVariant(t *testing.T) {
+	fen, _ := FEN("7k/8/8/8/8/1Q6/8/1K6 w - - 0 1")
+	g := NewGame(fen)
+	g.SetVariant(VariantThreeCheck)
+
+	moves := []string{"Qb2", "Kg8", "Qb3", "Kh8"}
+	for _, m := range moves {
+		if err := g.PushMove(m, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := g.CheckCount(White); got != 2 {
+		t.Fatalf("expected 2 checks delivered but got %d", got)
+	}
+	if g.Outcome() != NoOutcome {
+		t.Fatalf("expected game in progress but got %s", g.Outcome())
+	}
+
+	// The third check wins immediately.
+	if err := g.PushMove("Qb2", nil); err != nil {
+		t.Fatal(err)
+	}
+	if g.Outcome() != WhiteWon || g.Method() != ThirdCheck {
+		t.Fatalf("expected white win by third check but got %s by %s", g.Outcome(), g.Method())
+	}
+
+	// The same sequence in a standard game is just three checks.
+	std := NewGame(fen)
+	for _, m := range append(moves, "Qb2") {
+		if err := std.PushMove(m, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if std.Outcome() != NoOutcome {
+		t.Fatalf("expected standard game in progress but got %s", std.Outcome())
+	}
+	if got := std.CheckCount(White); got != 3 {
+		t.Fatalf("expected 3 checks delivered but got %d", got)
+	}
+}
+
+func TestKingOfTheHillVariant(t *testing.T) {
+	fen, _ := FEN("7k/p7/8/8/8/4K3/P7/8 w - - 0 1")
+	g := NewGame(fen)
+	g.SetVariant(VariantKingOfTheHill)
+	if g.Outcome() != NoOutcome {
+		t.Fatalf("expected game in progress but got %s", g.Outcome())
+	}
+
+	// Reaching a central square wins on the spot.
+	if err := g.PushMove("Ke4", nil); err != nil {
+		t.Fatal(err)
+	}
+	if g.Outcome() != WhiteWon || g.Method() != KingInCenter {
+		t.Fatalf("expected white win in the center but got %s by %s", g.Outcome(), g.Method())
+	}
+
+	// The same move in a standard game does nothing.
+	std := NewGame(fen)
+	if err := std.PushMove("Ke4", nil); err != nil {
+		t.Fatal(err)
+	}
+	if std.Outcome() != NoOutcome {
+		t.Fatalf("expected standard game in progress but got %s", std.Outcome())
+	}
+}
@@ -60,6 +60,7 @@ const (
 	CommandName     // The command name (e.g., clk, eval)
 	CommandParam    // Command parameter
 	CommandEnd      // ]
+	DROP            // @ in variant drop moves (e.g. CrazyHouse "N@f3")
 )
 
 func (t TokenType) String() string {
@@ -95,6 +96,7 @@ func (t TokenType) String() string {
 		"CommandName",
 		"CommandParam",
 		"CommandEnd",
+		"DROP",
 	}
 
 	if t < 0 || int(t) >= len(types) {
@@ -121,6 +123,7 @@ type Lexer struct {
 	inComment      bool
 	inCommand      bool
 	inCommandParam bool
+	variant        bool
 }
 
 // NewLexer creates a new Lexer for the provided input text.
@@ -136,6 +139,17 @@ func NewLexer(input string) *Lexer {
 	return l
 }
 
+// NewVariantLexer creates a Lexer that also recognizes variant-only
+// notation, currently the CrazyHouse drop syntax ("N@f3", "@e4") via the
+// DROP token.  The standard NewLexer emits an Undefined token for "@" so
+// that variant PGNs in mixed databases are rejected cleanly rather than
+// mis-lexed.
+func NewVariantLexer(input string) *Lexer {
+	l := NewLexer(input)
+	l.variant = true
+	return l
+}
+
 func (l *Lexer) peekChar() byte {
 	if l.readPosition >= len(l.input) {
 		return 0
@@ -387,13 +401,24 @@ func (l *Lexer) readChar() {
 
 func (l *Lexer) readTagValue() Token {
 	l.readChar() // skip opening quote
-	position := l.position
+	var sb strings.Builder
 	for l.ch != '"' && l.ch != 0 {
+		if l.ch == '\\' {
+			l.readChar()
+			if l.ch == 0 {
+				break
+			}
+			// Per the PGN spec only \" and \\ are escapes; keep the
+			// backslash for anything else.
+			if l.ch != '"' && l.ch != '\\' {
+				sb.WriteByte('\\')
+			}
+		}
+		sb.WriteByte(l.ch)
 		l.readChar()
 	}
-	value := l.input[position:l.position]
 	l.readChar() // skip closing quote
-	return Token{Type: TagValue, Value: value}
+	return Token{Type: TagValue, Value: sb.String()}
 }
 
 func (l *Lexer) readTagKey() Token {
@@ -533,6 +558,14 @@ func (l *Lexer) NextToken() Token {
 	case 'x':
 		l.readChar()
 		return Token{Type: CAPTURE, Value: "x"}
+	case '@':
+		// CrazyHouse drop notation, only meaningful in variant mode.
+		// In standard mode fall through to an Undefined token so the
+		// parser rejects the move instead of mis-lexing it.
+		if l.variant {
+			l.readChar()
+			return Token{Type: DROP, Value: "@"}
+		}
 	case '*':
 		fallthrough
 	case '-':
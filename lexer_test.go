@@ -87,6 +87,78 @@ func TestLexer(t *testing.T) {
 	}
 }
 
+func TestGluedMoveNumbers(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []Token
+	}{
+		{
+			name:  "Move number glued to move",
+			input: "1.e4 e5 2.Nf3",
+			expected: []Token{
+				{Type: MoveNumber, Value: "1"},
+				{Type: DOT, Value: "."},
+				{Type: SQUARE, Value: "e4"},
+				{Type: SQUARE, Value: "e5"},
+				{Type: MoveNumber, Value: "2"},
+				{Type: DOT, Value: "."},
+				{Type: PIECE, Value: "N"},
+				{Type: SQUARE, Value: "f3"},
+			},
+		},
+		{
+			name:  "Ellipsis glued to move",
+			input: "1...e5",
+			expected: []Token{
+				{Type: MoveNumber, Value: "1"},
+				{Type: ELLIPSIS, Value: "..."},
+				{Type: SQUARE, Value: "e5"},
+			},
+		},
+		{
+			name:  "Mixed glued and spaced forms",
+			input: "1.e4 e5 2. Nf3 Nc6 3.Bb5",
+			expected: []Token{
+				{Type: MoveNumber, Value: "1"},
+				{Type: DOT, Value: "."},
+				{Type: SQUARE, Value: "e4"},
+				{Type: SQUARE, Value: "e5"},
+				{Type: MoveNumber, Value: "2"},
+				{Type: DOT, Value: "."},
+				{Type: PIECE, Value: "N"},
+				{Type: SQUARE, Value: "f3"},
+				{Type: PIECE, Value: "N"},
+				{Type: SQUARE, Value: "c6"},
+				{Type: MoveNumber, Value: "3"},
+				{Type: DOT, Value: "."},
+				{Type: PIECE, Value: "B"},
+				{Type: SQUARE, Value: "b5"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lexer := NewLexer(tt.input)
+
+			for i, expected := range tt.expected {
+				token := lexer.NextToken()
+				if token.Type != expected.Type || token.Value != expected.Value {
+					t.Errorf("Token %d - Expected {%v, %q}, got {%v, %q}",
+						i, expected.Type, expected.Value, token.Type, token.Value)
+				}
+			}
+
+			// Verify we get EOF after all tokens
+			token := lexer.NextToken()
+			if token.Type != EOF {
+				t.Errorf("Expected EOF token after capture, got %v", token.Type)
+			}
+		})
+	}
+}
+
 func Test_TagKey(t *testing.T) {
 	input := "[Opening \"King's Indian Attack, General\"]"
 	lexer := NewLexer(input)
@@ -1069,3 +1141,45 @@ func TestSingleFromPosPGN(t *testing.T) {
 		t.Errorf("Expected EOF, got %v", tok.Type)
 	}
 }
+
+func TestDropNotation(t *testing.T) {
+	input := "1. N@f3 @e4"
+
+	// In variant mode the CrazyHouse drop syntax tokenizes cleanly.
+	lexer := NewVariantLexer(input)
+	expectedTokens := []struct {
+		typ   TokenType
+		value string
+	}{
+		{MoveNumber, "1"},
+		{DOT, "."},
+		{PIECE, "N"},
+		{DROP, "@"},
+		{SQUARE, "f3"},
+		{DROP, "@"},
+		{SQUARE, "e4"},
+	}
+	for i, expected := range expectedTokens {
+		token := lexer.NextToken()
+		if token.Type != expected.typ || token.Value != expected.value {
+			t.Errorf("Token %d - Expected {%v, %q}, got {%v, %q}",
+				i, expected.typ, expected.value, token.Type, token.Value)
+		}
+	}
+	if token := lexer.NextToken(); token.Type != EOF {
+		t.Errorf("Expected EOF token, got %v", token.Type)
+	}
+
+	// The standard lexer rejects "@" as Undefined instead of mis-lexing
+	// the surrounding move.
+	lexer = NewLexer("N@f3")
+	if token := lexer.NextToken(); token.Type != PIECE || token.Value != "N" {
+		t.Errorf("Expected {PIECE, \"N\"}, got {%v, %q}", token.Type, token.Value)
+	}
+	if token := lexer.NextToken(); token.Type != Undefined || token.Value != "@" {
+		t.Errorf("Expected {Undefined, \"@\"}, got {%v, %q}", token.Type, token.Value)
+	}
+	if token := lexer.NextToken(); token.Type != SQUARE || token.Value != "f3" {
+		t.Errorf("Expected {SQUARE, \"f3\"}, got {%v, %q}", token.Type, token.Value)
+	}
+}
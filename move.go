@@ -1,6 +1,11 @@
 package chess
 
-import "strings"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
 
 // A MoveTag represents a notable consequence of a move.
 type MoveTag uint16
@@ -16,24 +21,44 @@ const (
 	EnPassant
 	// Check indicates that the move puts the opposing player in check.
 	Check
+	// Promotion indicates that the move promotes a pawn.  The piece
+	// promoted to is available via Promo.
+	Promotion
 	// inCheck indicates that the move puts the moving player in check and
 	// is therefore invalid.
 	inCheck
 )
 
+// A CheckType classifies how a move gives check.
+type CheckType uint8
+
+const (
+	// NoCheck indicates that the move doesn't give check.
+	NoCheck CheckType = iota
+	// NormalCheck indicates that the moved piece itself gives check.
+	NormalCheck
+	// DiscoveredCheck indicates that a piece uncovered by the move gives check.
+	DiscoveredCheck
+	// DoubleCheck indicates that the moved piece and a discovered attacker
+	// give check at the same time.
+	DoubleCheck
+)
+
 // A Move is the movement of a piece from one square to another.
 type Move struct {
-	parent   *Move
-	position *Position // Position after the move
-	nag      string
-	comments string
-	command  map[string]string // Store commands as key-value pairs
-	children []*Move           // Main line and variations
-	number   uint
-	tags     MoveTag
-	s1       Square
-	s2       Square
-	promo    PieceType
+	parent      *Move
+	position    *Position // Position after the move
+	nags        []string  // Numeric Annotation Glyphs, in order of appearance
+	comments    string
+	preComments string            // Comment appearing before the move in the movetext
+	command     map[string]string // Store commands as key-value pairs
+	children    []*Move           // Main line and variations
+	number      uint
+	tags        MoveTag
+	checkType   CheckType
+	s1          Square
+	s2          Square
+	promo       PieceType
 }
 
 // String returns a string useful for debugging.  String doesn't return
@@ -57,6 +82,22 @@ func (m *Move) Promo() PieceType {
 	return m.promo
 }
 
+// IsPromotion returns true if the move promotes a pawn.
+func (m *Move) IsPromotion() bool {
+	return m.promo != NoPieceType
+}
+
+// CheckType returns how the move gives check: by the moved piece
+// (NormalCheck), by a piece the move uncovered (DiscoveredCheck), or
+// by both at once (DoubleCheck).  ok is false when the move doesn't
+// give check.
+func (m *Move) CheckType() (CheckType, bool) {
+	if m.checkType == NoCheck {
+		return NoCheck, false
+	}
+	return m.checkType, true
+}
+
 // HasTag returns true if the move contains the MoveTag given.
 func (m *Move) HasTag(tag MoveTag) bool {
 	return (tag & m.tags) > 0
@@ -99,12 +140,117 @@ func (m *Move) Comments() string {
 	return m.comments
 }
 
+// SetPreComments sets the comment rendered before the move rather than
+// after it, as in "1. {a novelty} e4".
+func (m *Move) SetPreComments(comment string) {
+	m.preComments = comment
+}
+
+// PreComments returns the comment appearing before the move in the
+// movetext, or the empty string if the move has none.
+func (m *Move) PreComments() string {
+	return m.preComments
+}
+
+// NAG returns the move's NAGs joined by a space (e.g. "$1 $15"), or the
+// empty string if the move has none.
 func (m *Move) NAG() string {
-	return m.nag
+	return strings.Join(m.nags, " ")
+}
+
+// NAGs returns all of the move's NAGs in the order they appear.
+func (m *Move) NAGs() []string {
+	return m.nags
 }
 
+// SetNAG replaces the move's NAGs with the given NAG.  An empty string
+// clears them.
 func (m *Move) SetNAG(nag string) {
-	m.nag = nag
+	if nag == "" {
+		m.nags = nil
+		return
+	}
+	m.nags = []string{nag}
+}
+
+// AddNAG appends a NAG to the move.
+func (m *Move) AddNAG(nag string) {
+	m.nags = append(m.nags, nag)
+}
+
+// ElapsedTime returns the time spent on the move as recorded in a
+// [%emt h:mm:ss] command.  The bool is false when the move has no %emt
+// command or its value cannot be parsed.
+func (m *Move) ElapsedTime() (time.Duration, bool) {
+	value, ok := m.GetCommand("emt")
+	if !ok {
+		return 0, false
+	}
+	return parseClockDuration(value)
+}
+
+// SetElapsedTime records the time spent on the move as a [%emt h:mm:ss]
+// command, which the PGN writer emits alongside any other commands.
+func (m *Move) SetElapsedTime(d time.Duration) {
+	m.SetCommand("emt", formatClockDuration(d))
+}
+
+// Eval returns the engine evaluation recorded in a [%eval ...] command,
+// as written by lichess and most analysis tools.  For a mate score such
+// as "#3" or "#-2", mate is the signed number of moves until mate
+// (positive when White mates) and pawns is 0.  Otherwise pawns holds the
+// decimal pawn advantage from White's perspective and mate is 0.  ok is
+// false when the move has no eval command or its value cannot be parsed.
+func (m *Move) Eval() (pawns float64, mate int, ok bool) {
+	value, ok := m.GetCommand("eval")
+	if !ok {
+		return 0, 0, false
+	}
+	if rest, found := strings.CutPrefix(value, "#"); found {
+		n, err := strconv.Atoi(rest)
+		if err != nil || n == 0 {
+			return 0, 0, false
+		}
+		return 0, n, true
+	}
+	p, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return p, 0, true
+}
+
+// parseClockDuration parses the h:mm:ss clock format used by the %clk
+// and %emt commands, with optional fractional seconds.
+func parseClockDuration(s string) (time.Duration, bool) {
+	const clockParts = 3
+	parts := strings.Split(s, ":")
+	if len(parts) != clockParts {
+		return 0, false
+	}
+	hours, err1 := strconv.Atoi(parts[0])
+	minutes, err2 := strconv.Atoi(parts[1])
+	seconds, err3 := strconv.ParseFloat(parts[2], 64)
+	if err1 != nil || err2 != nil || err3 != nil ||
+		hours < 0 || minutes < 0 || seconds < 0 {
+		return 0, false
+	}
+	return time.Duration(hours)*time.Hour +
+		time.Duration(minutes)*time.Minute +
+		time.Duration(seconds*float64(time.Second)), true
+}
+
+// formatClockDuration formats a duration in the h:mm:ss clock format
+// used by the %clk and %emt commands, keeping fractional seconds only
+// when present.
+func formatClockDuration(d time.Duration) string {
+	hours := d / time.Hour
+	minutes := d % time.Hour / time.Minute
+	seconds := d % time.Minute
+	if seconds%time.Second == 0 {
+		return fmt.Sprintf("%d:%02d:%02d", hours, minutes, seconds/time.Second)
+	}
+	return fmt.Sprintf("%d:%02d:%06.3f", hours, minutes, float64(seconds)/float64(time.Second))
 }
 
 func (m *Move) Parent() *Move {
@@ -134,22 +280,41 @@ func (m *Move) FullMoveNumber() int {
 	return m.Number()
 }
 
-// Ply returns the half-move number (increments every move).
+// Ply returns the half-move number of the played move (increments every
+// move): 1 for White's first move, 2 for Black's reply, and so on.  The
+// resulting position's Position.Ply is always one greater, since it
+// numbers the half-move about to be played.
 func (m *Move) Ply() int {
-	if m == nil {
+	if m == nil || m.position == nil {
 		return 0
 	}
-	if m.position == nil {
-		return 0
+	// The stored position is after the move has been played, so the ply
+	// of the move is one less than the position's.  Deriving it this way
+	// keeps the two methods consistent and covers moves without a
+	// recorded move number.
+	return m.position.Ply() - 1
+}
+
+// PGNString returns the move rendered in isolation the way it appears in
+// movetext: the move number ("14." for White, "14..." for Black) followed
+// by the move in algebraic notation, any NAGs, and the inline comment.
+// Returns an empty string for a move without a recorded parent position.
+func (m *Move) PGNString() string {
+	if m == nil || m.parent == nil || m.parent.position == nil {
+		return ""
 	}
-	moveNumber := int(m.number)
-	// we reverse the color because the position is after the move has been played
-	if m.position.turn == Black {
-		// After the move, it's White's turn, so the move was by Black
-		return (moveNumber-1)*2 + 1
+	pre := m.parent.position
+	var sb strings.Builder
+	sb.WriteString(strconv.Itoa(pre.moveCount))
+	if pre.Turn() == White {
+		sb.WriteString(". ")
+	} else {
+		sb.WriteString("... ")
 	}
-	// After the move, it's Black's turn, so the move was by White
-	return (moveNumber)*2 + 0
+	sb.WriteString(AlgebraicNotation{}.Encode(pre, m))
+	writeNAGs(m, &sb)
+	writeComments(m, &sb)
+	return sb.String()
 }
 
 // Clone returns a deep copy of a move.
@@ -162,11 +327,13 @@ func (m *Move) Clone() *Move {
 	ret := &Move{}
 	ret.parent = nil
 	ret.position = m.position.copy()
-	ret.nag = m.nag
+	ret.nags = append([]string(nil), m.nags...)
 	ret.comments = m.comments
+	ret.preComments = m.preComments
 	ret.children = make([]*Move, 0)
 	ret.number = m.number
 	ret.tags = m.tags
+	ret.checkType = m.checkType
 	ret.s1 = m.s1
 	ret.s2 = m.s2
 	ret.promo = m.promo
@@ -2,7 +2,9 @@ package chess
 
 import (
 	"log"
+	"strings"
 	"testing"
+	"time"
 )
 
 type moveTest struct {
@@ -369,7 +371,7 @@ func TestAddComment(t *testing.T) {
 
 func TestNAGReturnsCorrectValue(t *testing.T) {
 	t.Run("NAGReturnsCorrectValue", func(t *testing.T) {
-		move := &Move{nag: "!!"}
+		move := &Move{nags: []string{"!!"}}
 		expected := "!!"
 		if move.NAG() != expected {
 			t.Fatalf("expected %v but got %v", expected, move.NAG())
@@ -447,7 +449,7 @@ func assertMovesAreEqual(t *testing.T, m1, m2 *Move) {
 	if m1.position.String() != m2.position.String() {
 		t.Fatalf("cloned mv %s position is not the same", m1)
 	}
-	if m1.nag != m2.nag {
+	if m1.NAG() != m2.NAG() {
 		t.Fatalf("cloned mv %s nag is not the same", m1)
 	}
 	if m1.comments != m2.comments {
@@ -504,3 +506,150 @@ func TestMoveClone(t *testing.T) {
 		}
 	}
 }
+
+func TestMoveElapsedTime(t *testing.T) {
+	parse := func(pgn string) *Game {
+		tokens, err := TokenizeGame(&GameScanned{Raw: pgn})
+		if err != nil {
+			t.Fatal("recieved unexpected error", err)
+		}
+		game, err := NewParser(tokens).Parse()
+		if err != nil {
+			t.Fatal("recieved unexpected error", err)
+		}
+		return game
+	}
+	game := parse("1. e4 {[%emt 0:00:07]} e5 {[%clk 0:03:00]} 2. Nf3 {[%emt 0:01:02.500]} *")
+	moves := game.Moves()
+
+	d, ok := moves[0].ElapsedTime()
+	if !ok || d != 7*time.Second {
+		t.Fatalf("expected 7s but got %v %t", d, ok)
+	}
+	// A move with only %clk has no elapsed time.
+	if _, ok := moves[1].ElapsedTime(); ok {
+		t.Fatal("expected no elapsed time for move without an emt command")
+	}
+	// Fractional seconds are preserved.
+	d, ok = moves[2].ElapsedTime()
+	if !ok || d != time.Minute+2*time.Second+500*time.Millisecond {
+		t.Fatalf("expected 1m2.5s but got %v %t", d, ok)
+	}
+
+	// The writer round-trips the command.
+	out := game.String()
+	if !strings.Contains(out, "[%emt 0:00:07]") {
+		t.Fatalf("expected output to contain %%emt command but got %s", out)
+	}
+	reparsed := parse(out)
+	if d, ok := reparsed.Moves()[0].ElapsedTime(); !ok || d != 7*time.Second {
+		t.Fatalf("expected 7s after round trip but got %v %t", d, ok)
+	}
+}
+
+func TestMoveSetElapsedTime(t *testing.T) {
+	m := &Move{}
+	m.SetElapsedTime(time.Hour + 23*time.Minute + 45*time.Second)
+	if value, _ := m.GetCommand("emt"); value != "1:23:45" {
+		t.Fatalf("expected emt 1:23:45 but got %s", value)
+	}
+	m.SetElapsedTime(7*time.Second + 500*time.Millisecond)
+	if value, _ := m.GetCommand("emt"); value != "0:00:07.500" {
+		t.Fatalf("expected emt 0:00:07.500 but got %s", value)
+	}
+	if d, ok := m.ElapsedTime(); !ok || d != 7*time.Second+500*time.Millisecond {
+		t.Fatalf("expected 7.5s but got %v %t", d, ok)
+	}
+}
+
+func TestPromotionTag(t *testing.T) {
+	pos := unsafeFEN("7k/4P3/8/8/8/8/8/7K w - - 0 1")
+	found := 0
+	for _, m := range pos.ValidMoves() {
+		move := m
+		if move.promo == NoPieceType {
+			if move.HasTag(Promotion) || move.IsPromotion() {
+				t.Fatalf("expected no promotion tag on %s", move.String())
+			}
+			continue
+		}
+		found++
+		if !move.HasTag(Promotion) {
+			t.Fatalf("expected promotion tag on %s", move.String())
+		}
+		if !move.IsPromotion() {
+			t.Fatalf("expected IsPromotion for %s", move.String())
+		}
+	}
+	if found != 4 {
+		t.Fatalf("expected 4 promotion moves but got %d", found)
+	}
+}
+
+func TestMoveCheckType(t *testing.T) {
+	findMove := func(t *testing.T, pos *Position, uci string) *Move {
+		t.Helper()
+		for _, m := range pos.ValidMoves() {
+			if m.String() == uci {
+				move := m
+				return &move
+			}
+		}
+		t.Fatalf("move %s not found", uci)
+		return nil
+	}
+
+	// The e4 knight blocks the e1 rook's line to the black king.
+	pos := unsafeFEN("4k3/8/8/8/4N3/8/8/4RK2 w - - 0 1")
+
+	// Retreating the knight uncovers the rook: discovered check.
+	if ct, ok := findMove(t, pos, "e4c3").CheckType(); !ok || ct != DiscoveredCheck {
+		t.Fatalf("expected discovered check but got %v %t", ct, ok)
+	}
+	// Nd6 attacks the king itself while uncovering the rook: double check.
+	if ct, ok := findMove(t, pos, "e4d6").CheckType(); !ok || ct != DoubleCheck {
+		t.Fatalf("expected double check but got %v %t", ct, ok)
+	}
+	// A quiet king move gives no check at all.
+	if ct, ok := findMove(t, pos, "f1g2").CheckType(); ok || ct != NoCheck {
+		t.Fatalf("expected no check but got %v %t", ct, ok)
+	}
+
+	// Without the knight the rook checks directly.
+	pos = unsafeFEN("4k3/8/8/8/8/8/8/4RK2 w - - 0 1")
+	if ct, ok := findMove(t, pos, "e1e7").CheckType(); !ok || ct != NormalCheck {
+		t.Fatalf("expected normal check but got %v %t", ct, ok)
+	}
+}
+
+func TestMovePGNString(t *testing.T) {
+	g := NewGame()
+	for _, san := range []string{"e4", "e5", "Nf3", "Nc6"} {
+		if err := g.PushMove(san, nil); err != nil {
+			t.Fatal("recieved unexpected error", err)
+		}
+	}
+	moves := g.Moves()
+
+	// White move.
+	if s := moves[0].PGNString(); s != "1. e4" {
+		t.Fatalf("expected 1. e4 but got %q", s)
+	}
+
+	// Black move carries the ellipsis.
+	if s := moves[1].PGNString(); s != "1... e5" {
+		t.Fatalf("expected 1... e5 but got %q", s)
+	}
+
+	// NAG and comment render like movetext.
+	moves[2].SetNAG("!")
+	moves[2].SetComment("develops")
+	if s := moves[2].PGNString(); s != "2. Nf3! {develops}" {
+		t.Fatalf("expected 2. Nf3! {develops} but got %q", s)
+	}
+
+	// The root move has no parent position to render from.
+	if s := g.rootMove.PGNString(); s != "" {
+		t.Fatalf("expected empty string for root move but got %q", s)
+	}
+}
@@ -3,6 +3,7 @@ package chess
 import (
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 )
@@ -89,11 +90,20 @@ type Decoder interface {
 	Decode(pos *Position, s string) (*Move, error)
 }
 
+// Validator is the interface implemented by objects that can check a
+// move string against a position without the caller needing the decoded
+// move.  An error is returned if the string could not be decoded or the
+// move is not legal in the position.
+type Validator interface {
+	Validate(pos *Position, text string) error
+}
+
 // Notation is the interface implemented by objects that can
-// encode and decode moves.
+// encode, decode, and validate moves.
 type Notation interface {
 	Encoder
 	Decoder
+	Validator
 }
 
 // UCINotation is a more computer friendly alternative to algebraic
@@ -179,10 +189,37 @@ func (UCINotation) Decode(pos *Position, s string) (*Move, error) {
 	return &m, nil
 }
 
+// Validate implements the Validator interface.  Beyond the syntax
+// checks Decode performs, the move must be legal in the position.
+// Decoding is done against a nil position, so no new positions are
+// allocated.
+func (un UCINotation) Validate(pos *Position, text string) error {
+	m, err := un.Decode(nil, text)
+	if err != nil {
+		return err
+	}
+	if pos == nil {
+		return nil
+	}
+	for _, valid := range pos.ValidMoves() {
+		if valid.s1 == m.s1 && valid.s2 == m.s2 && valid.promo == m.promo {
+			return nil
+		}
+	}
+	return fmt.Errorf("chess: move %s is not valid", text)
+}
+
 // AlgebraicNotation (or Standard Algebraic Notation) is the
 // official chess notation used by FIDE. Examples: e4, e5,
 // O-O (short castling), e8=Q (promotion).
-type AlgebraicNotation struct{}
+type AlgebraicNotation struct {
+	// ValidateMoveNumbers accepts a leading move number in decoded
+	// strings (e.g. "17. Qd5" or "17... Qd5") and rejects the move when
+	// the number or the dot count does not match the position's move
+	// count and turn.  Without it, leading move numbers are a decode
+	// error as before.  This catches misaligned PGN sources early.
+	ValidateMoveNumbers bool
+}
 
 // String implements the fmt.Stringer interface and returns
 // the notation's name.
@@ -341,7 +378,15 @@ func (mc moveComponents) generateOptions() []string {
 }
 
 // Decode implements the Decoder interface.
-func (AlgebraicNotation) Decode(pos *Position, s string) (*Move, error) {
+func (an AlgebraicNotation) Decode(pos *Position, s string) (*Move, error) {
+	if an.ValidateMoveNumbers {
+		trimmed, err := validateMoveNumber(pos, s)
+		if err != nil {
+			return nil, err
+		}
+		s = trimmed
+	}
+
 	// Parse move components
 	components, err := algebraicNotationParts(s)
 	if err != nil {
@@ -352,6 +397,7 @@ func (AlgebraicNotation) Decode(pos *Position, s string) (*Move, error) {
 	cleanedInput := components.clean()
 
 	// Try matching against valid moves
+	var ambiguous []string
 	for _, m := range pos.ValidMoves() {
 		// Encode current move
 		moveStr := AlgebraicNotation{}.Encode(pos, &m)
@@ -367,6 +413,17 @@ func (AlgebraicNotation) Decode(pos *Position, s string) (*Move, error) {
 			return &m, nil
 		}
 
+		// Track moves the input would match were their required origin
+		// disambiguation removed; more than one means s is ambiguous.
+		if notationParts.originFile != "" || notationParts.originRank != "" {
+			stripped := notationParts
+			stripped.originFile, stripped.originRank = "", ""
+			if cleanedInput == stripped.clean() {
+				ambiguous = append(ambiguous, moveStr)
+				continue
+			}
+		}
+
 		// Try alternative notations
 		for _, opt := range components.generateOptions() {
 			if opt == notationParts.clean() {
@@ -375,9 +432,55 @@ func (AlgebraicNotation) Decode(pos *Position, s string) (*Move, error) {
 		}
 	}
 
+	if len(ambiguous) > 1 {
+		return nil, &AmbiguousMoveError{Notation: s, Candidates: ambiguous}
+	}
+
 	return nil, fmt.Errorf("chess: move %s is not valid", s)
 }
 
+// Validate implements the Validator interface.  It reports the same
+// errors Decode would, including AmbiguousMoveError for under-specified
+// moves, without the caller keeping the decoded move.
+func (an AlgebraicNotation) Validate(pos *Position, text string) error {
+	_, err := an.Decode(pos, text)
+	return err
+}
+
+// validateMoveNumber strips a leading move number such as "17." or
+// "17..." from s and checks it against the position's move count and
+// turn.  Strings without a leading number pass through unchanged.
+func validateMoveNumber(pos *Position, s string) (string, error) {
+	digits := 0
+	for digits < len(s) && s[digits] >= '0' && s[digits] <= '9' {
+		digits++
+	}
+	if digits == 0 {
+		return s, nil
+	}
+	number, err := strconv.Atoi(s[:digits])
+	if err != nil {
+		return "", fmt.Errorf("chess: invalid move number in %q", s)
+	}
+	dots := 0
+	for digits+dots < len(s) && s[digits+dots] == '.' {
+		dots++
+	}
+	turn := White
+	switch dots {
+	case 1:
+	case 3:
+		turn = Black
+	default:
+		return "", fmt.Errorf("chess: invalid move number prefix in %q", s)
+	}
+	if number != pos.moveCount || turn != pos.turn {
+		return "", fmt.Errorf("chess: move number %s does not match position (move %d, %s to move)",
+			s[:digits+dots], pos.moveCount, pos.turn.Name())
+	}
+	return strings.TrimSpace(s[digits+dots:]), nil
+}
+
 // LongAlgebraicNotation is a fully expanded version of
 // algebraic notation in which the starting and ending
 // squares are specified.
@@ -417,6 +520,11 @@ func (LongAlgebraicNotation) Decode(pos *Position, s string) (*Move, error) {
 	return AlgebraicNotation{}.Decode(pos, s)
 }
 
+// Validate implements the Validator interface.
+func (LongAlgebraicNotation) Validate(pos *Position, text string) error {
+	return AlgebraicNotation{}.Validate(pos, text)
+}
+
 func getCheckChar(pos *Position, move *Move) string {
 	if !move.HasTag(Check) {
 		return ""
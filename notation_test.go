@@ -1,6 +1,7 @@
 package chess
 
 import (
+	"errors"
 	"testing"
 )
 
@@ -116,7 +117,7 @@ func TestUCINotationDecode(t *testing.T) {
 			name:        "valid move with promotion",
 			pos:         unsafeFEN("8/P7/8/8/8/8/8/8 w - - 0 1"),
 			input:       "a7a8q",
-			want:        &Move{s1: A7, s2: A8, promo: Queen},
+			want:        &Move{s1: A7, s2: A8, promo: Queen, tags: Promotion},
 			expectedPos: unsafeFEN("Q7/8/8/8/8/8/8/8 b - - 0 1"),
 			wantErr:     false,
 		},
@@ -400,3 +401,109 @@ func BenchmarkPromotionEncoding(b *testing.B) {
 		notation.Encode(promoPos, promoMove)
 	}
 }
+
+func TestAlgebraicNotationValidateMoveNumbers(t *testing.T) {
+	strict := AlgebraicNotation{ValidateMoveNumbers: true}
+	pos := StartingPosition()
+
+	// A matching move number decodes.
+	m, err := strict.Decode(pos, "1. e4")
+	if err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	if m.String() != "e2e4" {
+		t.Fatalf("expected move e2e4 but got %s", m.String())
+	}
+	// Plain SAN still decodes.
+	if _, err := strict.Decode(pos, "e4"); err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	// A glued number decodes too.
+	if _, err := strict.Decode(pos, "1.e4"); err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+
+	// Wrong move number, wrong turn, and malformed prefixes are rejected.
+	if _, err := strict.Decode(pos, "17. e4"); err == nil {
+		t.Fatal("expected error for wrong move number")
+	}
+	if _, err := strict.Decode(pos, "1... e4"); err == nil {
+		t.Fatal("expected error for wrong turn")
+	}
+	if _, err := strict.Decode(pos, "1.. e4"); err == nil {
+		t.Fatal("expected error for malformed prefix")
+	}
+
+	// Black's ellipsis form validates against the turn.
+	black := pos.Update(&Move{s1: E2, s2: E4})
+	if _, err := strict.Decode(black, "1... e5"); err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	if _, err := strict.Decode(black, "1. e5"); err == nil {
+		t.Fatal("expected error for white prefix on black move")
+	}
+
+	// The default notation keeps rejecting leading move numbers.
+	if _, err := (AlgebraicNotation{}).Decode(pos, "1. e4"); err == nil {
+		t.Fatal("expected error decoding numbered move without the option")
+	}
+}
+
+func TestAlgebraicNotationAmbiguousMove(t *testing.T) {
+	// Both knights can reach d7, so the bare "Nd7" is ambiguous.
+	pos := unsafeFEN("3k4/8/1n3n2/8/8/8/8/3K4 b - - 0 1")
+	_, err := AlgebraicNotation{}.Decode(pos, "Nd7")
+	if err == nil {
+		t.Fatal("expected error decoding ambiguous move")
+	}
+	var ambErr *AmbiguousMoveError
+	if !errors.As(err, &ambErr) {
+		t.Fatalf("expected AmbiguousMoveError but got %T", err)
+	}
+	if ambErr.Notation != "Nd7" {
+		t.Fatalf("expected notation Nd7 but got %s", ambErr.Notation)
+	}
+	if len(ambErr.Candidates) != 2 {
+		t.Fatalf("expected 2 candidates but got %v", ambErr.Candidates)
+	}
+
+	// Disambiguated forms still decode.
+	for _, san := range []string{"Nbd7", "Nfd7"} {
+		if _, err := (AlgebraicNotation{}).Decode(pos, san); err != nil {
+			t.Fatal("recieved unexpected error", err)
+		}
+	}
+
+	// A move with a single legal interpretation stays a plain decode.
+	start := unsafeFEN("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1")
+	if _, err := (AlgebraicNotation{}).Decode(start, "Nf3"); err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+
+	// A move no piece can make keeps the generic error.
+	if _, err := (AlgebraicNotation{}).Decode(start, "Ne5"); err == nil {
+		t.Fatal("expected error decoding invalid move")
+	} else if errors.As(err, &ambErr) {
+		t.Fatal("expected generic error but got AmbiguousMoveError")
+	}
+}
+
+func TestNotationValidate(t *testing.T) {
+	pos := unsafeFEN("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1")
+	notations := []Notation{UCINotation{}, AlgebraicNotation{}, LongAlgebraicNotation{}}
+	valid := []string{"e2e4", "e4", "e2e4"}
+	invalid := []string{"e2e5", "e5", "e2e5"}
+
+	for i, n := range notations {
+		if err := n.Validate(pos, valid[i]); err != nil {
+			t.Fatal("recieved unexpected error", err)
+		}
+		if err := n.Validate(pos, invalid[i]); err == nil {
+			t.Fatalf("expected error validating %s with %s", invalid[i], n)
+		}
+		// Garbage never validates.
+		if err := n.Validate(pos, "xyzzy"); err == nil {
+			t.Fatalf("expected error validating garbage with %s", n)
+		}
+	}
+}
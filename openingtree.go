@@ -0,0 +1,109 @@
+package chess
+
+import (
+	"io"
+	"sort"
+)
+
+// OpeningMoveStats aggregates how often a move was played from a position
+// in a corpus and how the games that played it ended.
+type OpeningMoveStats struct {
+	Move      string // move in UCI-style coordinates (e.g. "e2e4")
+	Count     int
+	WhiteWins int
+	BlackWins int
+	Draws     int
+}
+
+// OpeningTree is a position-keyed aggregation of the opening moves played
+// in a corpus of games.  For every position reached it records which
+// moves were played, how often, and the results of the games they came
+// from.  Build one with BuildOpeningTree.
+type OpeningTree struct {
+	nodes map[[16]byte]map[string]*OpeningMoveStats
+	games int
+}
+
+// BuildOpeningTree reads every game from the PGN source and aggregates
+// the mainline moves up to maxPly plies deep into an opening tree.  A
+// maxPly of 0 or less applies no depth limit.  An error is returned if a
+// game fails to parse.
+func BuildOpeningTree(r io.Reader, maxPly int) (*OpeningTree, error) {
+	tree := &OpeningTree{
+		nodes: make(map[[16]byte]map[string]*OpeningMoveStats),
+	}
+	scanner := NewScanner(r)
+	for scanner.HasNext() {
+		game, err := scanner.ParseNext()
+		if err != nil {
+			return nil, err
+		}
+		tree.addGame(game, maxPly)
+	}
+	return tree, nil
+}
+
+// Games returns the number of games aggregated into the tree.
+func (t *OpeningTree) Games() int {
+	return t.games
+}
+
+// MovesFromPosition returns the aggregated statistics for the moves
+// played from the given position, most frequent first.  It returns nil
+// when the position was never reached in the corpus.
+func (t *OpeningTree) MovesFromPosition(pos *Position) []OpeningMoveStats {
+	return t.MovesFromHash(pos.Hash())
+}
+
+// MovesFromHash is like MovesFromPosition but keyed by a position hash,
+// so callers that already track Position.Hash values avoid rehashing.
+func (t *OpeningTree) MovesFromHash(hash [16]byte) []OpeningMoveStats {
+	node, ok := t.nodes[hash]
+	if !ok {
+		return nil
+	}
+	ret := make([]OpeningMoveStats, 0, len(node))
+	for _, stats := range node {
+		ret = append(ret, *stats)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Count != ret[j].Count {
+			return ret[i].Count > ret[j].Count
+		}
+		return ret[i].Move < ret[j].Move
+	})
+	return ret
+}
+
+// addGame walks the game's mainline and folds each move into the tree.
+func (t *OpeningTree) addGame(g *Game, maxPly int) {
+	t.games++
+	outcome := g.Outcome()
+	pos := g.GetRootMove().position
+	for i, m := range g.Moves() {
+		if maxPly > 0 && i >= maxPly {
+			break
+		}
+		key := pos.Hash()
+		node, ok := t.nodes[key]
+		if !ok {
+			node = make(map[string]*OpeningMoveStats)
+			t.nodes[key] = node
+		}
+		stats, ok := node[m.String()]
+		if !ok {
+			stats = &OpeningMoveStats{Move: m.String()}
+			node[m.String()] = stats
+		}
+		stats.Count++
+		switch outcome {
+		case WhiteWon:
+			stats.WhiteWins++
+		case BlackWon:
+			stats.BlackWins++
+		case Draw:
+			stats.Draws++
+		}
+		pos = m.Position()
+	}
+}
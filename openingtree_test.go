@@ -0,0 +1,65 @@
+package chess
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBuildOpeningTree(t *testing.T) {
+	file, err := os.Open(filepath.Join("fixtures/pgns", "multi_game.pgn"))
+	if err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	defer file.Close()
+
+	tree, err := BuildOpeningTree(file, 0)
+	if err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	if tree.Games() != 4 {
+		t.Fatalf("expected 4 games but got %d", tree.Games())
+	}
+
+	// All four games open 1. e4 and are won by white.
+	stats := tree.MovesFromPosition(StartingPosition())
+	if len(stats) != 1 {
+		t.Fatalf("expected 1 first move but got %d", len(stats))
+	}
+	if stats[0].Move != "e2e4" || stats[0].Count != 4 || stats[0].WhiteWins != 4 {
+		t.Fatalf("expected e2e4 played 4 times with 4 white wins but got %+v", stats[0])
+	}
+
+	// The reply 1... e5 is reachable through MovesFromHash as well.
+	afterE4 := StartingPosition().Update(&Move{s1: E2, s2: E4})
+	replies := tree.MovesFromHash(afterE4.Hash())
+	if len(replies) != 1 || replies[0].Move != "e7e5" || replies[0].Count != 4 {
+		t.Fatalf("expected e7e5 played 4 times but got %+v", replies)
+	}
+
+	// An unseen position has no entries.
+	afterD4 := StartingPosition().Update(&Move{s1: D2, s2: D4})
+	if tree.MovesFromPosition(afterD4) != nil {
+		t.Fatal("expected no moves for unseen position")
+	}
+}
+
+func TestBuildOpeningTreeMaxPly(t *testing.T) {
+	file, err := os.Open(filepath.Join("fixtures/pgns", "multi_game.pgn"))
+	if err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	defer file.Close()
+
+	tree, err := BuildOpeningTree(file, 1)
+	if err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	if stats := tree.MovesFromPosition(StartingPosition()); len(stats) != 1 {
+		t.Fatalf("expected 1 first move but got %d", len(stats))
+	}
+	afterE4 := StartingPosition().Update(&Move{s1: E2, s2: E4})
+	if tree.MovesFromPosition(afterE4) != nil {
+		t.Fatal("expected no replies beyond the ply limit")
+	}
+}
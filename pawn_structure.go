@@ -0,0 +1,228 @@
+package chess
+
+import "math/bits"
+
+// This file implements pawn-structure queries on the Board.  The helpers
+// identify standard structural features (doubled, isolated, passed, and
+// backward pawns) computed from the pawn bitboards.  They are intended for
+// evaluation and teaching tools rather than move generation.
+
+// pawnBB returns the pawn bitboard for the given color.
+func (b *Board) pawnBB(c Color) bitboard {
+	if c == White {
+		return b.bbWhitePawn
+	}
+	return b.bbBlackPawn
+}
+
+// pawnAttacksBB returns a bitboard of all squares attacked by pawns of the
+// given color.
+func pawnAttacksBB(pawns bitboard, c Color) bitboard {
+	if c == White {
+		capRight := (pawns & ^bbFileH & ^bbRank8) >> 9
+		capLeft := (pawns & ^bbFileA & ^bbRank8) >> 7
+		return capRight | capLeft
+	}
+	capRight := (pawns & ^bbFileH & ^bbRank1) << 7
+	capLeft := (pawns & ^bbFileA & ^bbRank1) << 9
+	return capRight | capLeft
+}
+
+// adjacentFilesBB returns the mask of the files adjacent to the given file.
+func adjacentFilesBB(f File) bitboard {
+	var bb bitboard
+	if f > FileA {
+		bb |= bbFiles[f-1]
+	}
+	if f < FileH {
+		bb |= bbFiles[f+1]
+	}
+	return bb
+}
+
+// ranksAheadBB returns the mask of all ranks strictly ahead of the given
+// rank from the given color's perspective.
+func ranksAheadBB(c Color, r Rank) bitboard {
+	var bb bitboard
+	if c == White {
+		for ahead := r + 1; ahead <= Rank8; ahead++ {
+			bb |= bbRanks[ahead]
+		}
+	} else {
+		for ahead := Rank1; ahead < r; ahead++ {
+			bb |= bbRanks[ahead]
+		}
+	}
+	return bb
+}
+
+// squaresOfBitboard expands a bitboard into its occupied squares in
+// ascending square order.
+func squaresOfBitboard(bb bitboard) []Square {
+	sqs := make([]Square, 0, bits.OnesCount64(uint64(bb)))
+	for sq := range numOfSquaresInBoard {
+		if bb.Occupied(Square(sq)) {
+			sqs = append(sqs, Square(sq))
+		}
+	}
+	return sqs
+}
+
+// DoubledPawns returns the squares of all pawns of the given color that
+// share a file with at least one other pawn of the same color.  The
+// squares are returned in ascending square order (A1 to H8).
+func (b *Board) DoubledPawns(c Color) []Square {
+	pawns := b.pawnBB(c)
+	var doubled bitboard
+	for f := FileA; f <= FileH; f++ {
+		filePawns := pawns & bbFiles[f]
+		if bits.OnesCount64(uint64(filePawns)) > 1 {
+			doubled |= filePawns
+		}
+	}
+	return squaresOfBitboard(doubled)
+}
+
+// IsolatedPawns returns the squares of all pawns of the given color with
+// no friendly pawns on either adjacent file.  The squares are returned in
+// ascending square order (A1 to H8).
+func (b *Board) IsolatedPawns(c Color) []Square {
+	pawns := b.pawnBB(c)
+	var isolated bitboard
+	for f := FileA; f <= FileH; f++ {
+		filePawns := pawns & bbFiles[f]
+		if filePawns == 0 {
+			continue
+		}
+		if pawns&adjacentFilesBB(f) == 0 {
+			isolated |= filePawns
+		}
+	}
+	return squaresOfBitboard(isolated)
+}
+
+// PassedPawns returns the squares of all pawns of the given color with no
+// opposing pawns ahead of them on their own file or either adjacent file.
+// The squares are returned in ascending square order (A1 to H8).
+func (b *Board) PassedPawns(c Color) []Square {
+	pawns := b.pawnBB(c)
+	enemyPawns := b.pawnBB(c.Other())
+	var passed bitboard
+	for _, sq := range squaresOfBitboard(pawns) {
+		frontSpan := (bbFiles[sq.File()] | adjacentFilesBB(sq.File())) & ranksAheadBB(c, sq.Rank())
+		if enemyPawns&frontSpan == 0 {
+			passed |= bbForSquare(sq)
+		}
+	}
+	return squaresOfBitboard(passed)
+}
+
+// TripledPawns returns the squares of all pawns of the given color on a
+// file holding three or more pawns of that color.  The squares are
+// returned in ascending square order (A1 to H8).
+func (b *Board) TripledPawns(c Color) []Square {
+	pawns := b.pawnBB(c)
+	var tripled bitboard
+	for f := FileA; f <= FileH; f++ {
+		filePawns := pawns & bbFiles[f]
+		if bits.OnesCount64(uint64(filePawns)) >= 3 {
+			tripled |= filePawns
+		}
+	}
+	return squaresOfBitboard(tripled)
+}
+
+// PawnIslands returns the number of pawn islands of the given color: the
+// groups of pawns on contiguous files, separated by at least one file
+// without a pawn.  Fewer islands generally means a healthier structure.
+// Returns 0 when the color has no pawns.
+func (b *Board) PawnIslands(c Color) int {
+	pawns := b.pawnBB(c)
+	islands := 0
+	inIsland := false
+	for f := FileA; f <= FileH; f++ {
+		if pawns&bbFiles[f] == 0 {
+			inIsland = false
+			continue
+		}
+		if !inIsland {
+			islands++
+			inIsland = true
+		}
+	}
+	return islands
+}
+
+// PawnsOnFile returns the number of pawns of the given color on the
+// given file.  Returns 0 for a file outside the board.
+func (pos *Position) PawnsOnFile(f File, c Color) int {
+	if f < FileA || f > FileH {
+		return 0
+	}
+	return bits.OnesCount64(uint64(pos.board.pawnBB(c) & bbFiles[f]))
+}
+
+// DoubledPawns returns the squares of all pawns of the given color that
+// share a file with at least one other pawn of the same color.  See
+// Board.DoubledPawns.
+func (pos *Position) DoubledPawns(c Color) []Square {
+	return pos.board.DoubledPawns(c)
+}
+
+// IsolatedPawns returns the squares of all pawns of the given color with
+// no friendly pawns on either adjacent file.  See Board.IsolatedPawns.
+func (pos *Position) IsolatedPawns(c Color) []Square {
+	return pos.board.IsolatedPawns(c)
+}
+
+// PassedPawns returns the squares of all pawns of the given color with no
+// opposing pawns ahead of them on their own file or either adjacent file.
+// See Board.PassedPawns.
+func (pos *Position) PassedPawns(c Color) []Square {
+	return pos.board.PassedPawns(c)
+}
+
+// TripledPawns returns the squares of all pawns of the given color on a
+// file holding three or more pawns of that color.  See
+// Board.TripledPawns.
+func (pos *Position) TripledPawns(c Color) []Square {
+	return pos.board.TripledPawns(c)
+}
+
+// PawnIslands returns the number of pawn islands of the given color.
+// See Board.PawnIslands.
+func (pos *Position) PawnIslands(c Color) int {
+	return pos.board.PawnIslands(c)
+}
+
+// BackwardPawns returns the squares of all pawns of the given color that
+// cannot be supported by a friendly pawn (no friendly pawn on an adjacent
+// file on the same or an earlier rank) and whose advance square is
+// attacked by an opposing pawn.  Pawns whose advance square is occupied
+// are considered blockaded rather than backward.  The squares are
+// returned in ascending square order (A1 to H8).
+func (b *Board) BackwardPawns(c Color) []Square {
+	pawns := b.pawnBB(c)
+	enemyAttacks := pawnAttacksBB(b.pawnBB(c.Other()), c.Other())
+	var backward bitboard
+	for _, sq := range squaresOfBitboard(pawns) {
+		// A pawn with potential support on an adjacent file is not backward.
+		support := adjacentFilesBB(sq.File()) & ^ranksAheadBB(c, sq.Rank())
+		if pawns&support != 0 {
+			continue
+		}
+		var stop Square
+		if c == White {
+			stop = sq + numOfSquaresInRow
+		} else {
+			stop = sq - numOfSquaresInRow
+		}
+		if stop < A1 || stop > H8 || b.isOccupied(stop) {
+			continue
+		}
+		if enemyAttacks&bbForSquare(stop) != 0 {
+			backward |= bbForSquare(sq)
+		}
+	}
+	return squaresOfBitboard(backward)
+}
@@ -0,0 +1,136 @@
+package chess
+
+import (
+	"testing"
+)
+
+func boardFromFEN(t *testing.T, fen string) *Board {
+	t.Helper()
+	b := &Board{}
+	if err := b.UnmarshalText([]byte(fen)); err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	return b
+}
+
+func squaresEqual(a, b []Square) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestDoubledPawns(t *testing.T) {
+	b := boardFromFEN(t, "8/2p5/8/2p5/8/2P5/2P5/8")
+	if sqs := b.DoubledPawns(White); !squaresEqual(sqs, []Square{C2, C3}) {
+		t.Fatalf("expected white doubled pawns [C2 C3] but got %v", sqs)
+	}
+	if sqs := b.DoubledPawns(Black); !squaresEqual(sqs, []Square{C5, C7}) {
+		t.Fatalf("expected black doubled pawns [C5 C7] but got %v", sqs)
+	}
+}
+
+func TestIsolatedPawns(t *testing.T) {
+	b := boardFromFEN(t, "8/8/8/8/8/8/P1PP4/8")
+	if sqs := b.IsolatedPawns(White); !squaresEqual(sqs, []Square{A2}) {
+		t.Fatalf("expected white isolated pawns [A2] but got %v", sqs)
+	}
+	if sqs := b.IsolatedPawns(Black); len(sqs) != 0 {
+		t.Fatalf("expected no black isolated pawns but got %v", sqs)
+	}
+}
+
+func TestPassedPawns(t *testing.T) {
+	b := boardFromFEN(t, "8/8/8/2p5/8/8/P7/8")
+	if sqs := b.PassedPawns(White); !squaresEqual(sqs, []Square{A2}) {
+		t.Fatalf("expected white passed pawns [A2] but got %v", sqs)
+	}
+	if sqs := b.PassedPawns(Black); !squaresEqual(sqs, []Square{C5}) {
+		t.Fatalf("expected black passed pawns [C5] but got %v", sqs)
+	}
+
+	// A pawn with an opposing pawn ahead on an adjacent file is not passed.
+	b = boardFromFEN(t, "8/3p4/8/8/8/8/2P5/8")
+	if sqs := b.PassedPawns(White); len(sqs) != 0 {
+		t.Fatalf("expected no white passed pawns but got %v", sqs)
+	}
+}
+
+func TestBackwardPawns(t *testing.T) {
+	// The e3 pawn has no supporting pawn behind it and its advance square
+	// e4 is attacked by the d5 pawn.
+	b := boardFromFEN(t, "8/8/8/3p4/3P4/4P3/8/8")
+	if sqs := b.BackwardPawns(White); !squaresEqual(sqs, []Square{E3}) {
+		t.Fatalf("expected white backward pawns [E3] but got %v", sqs)
+	}
+	if sqs := b.BackwardPawns(Black); len(sqs) != 0 {
+		t.Fatalf("expected no black backward pawns but got %v", sqs)
+	}
+}
+
+func TestPositionPawnQueries(t *testing.T) {
+	// White: doubled (and isolated) c-pawns plus an isolated a-pawn that
+	// is also passed.
+	pos := unsafeFEN("8/5ppp/8/2p5/2P5/2P5/P4PPP/8 w - - 0 1")
+
+	if got := pos.PawnsOnFile(FileC, White); got != 2 {
+		t.Fatalf("expected 2 white pawns on the c file but got %d", got)
+	}
+	if got := pos.PawnsOnFile(FileA, Black); got != 0 {
+		t.Fatalf("expected 0 black pawns on the a file but got %d", got)
+	}
+	if got := pos.PawnsOnFile(FileH+1, White); got != 0 {
+		t.Fatalf("expected 0 pawns off the board but got %d", got)
+	}
+
+	if sqs := pos.DoubledPawns(White); !squaresEqual(sqs, []Square{C3, C4}) {
+		t.Fatalf("expected white doubled pawns [C3 C4] but got %v", sqs)
+	}
+	if sqs := pos.IsolatedPawns(White); !squaresEqual(sqs, []Square{A2, C3, C4}) {
+		t.Fatalf("expected white isolated pawns [A2 C3 C4] but got %v", sqs)
+	}
+	if sqs := pos.PassedPawns(White); !squaresEqual(sqs, []Square{A2}) {
+		t.Fatalf("expected white passed pawns [A2] but got %v", sqs)
+	}
+	// The black c5 pawn is blockaded by white's own c-pawns, so neither
+	// side has another passed pawn.
+	if sqs := pos.PassedPawns(Black); len(sqs) != 0 {
+		t.Fatalf("expected no black passed pawns but got %v", sqs)
+	}
+}
+
+func TestTripledPawns(t *testing.T) {
+	// White has three pawns on the c file; Black only doubled pawns.
+	b := boardFromFEN(t, "8/2p5/8/2p5/2P5/2P5/2P5/8")
+	if sqs := b.TripledPawns(White); !squaresEqual(sqs, []Square{C2, C3, C4}) {
+		t.Fatalf("expected white tripled pawns [C2 C3 C4] but got %v", sqs)
+	}
+	if sqs := b.TripledPawns(Black); len(sqs) != 0 {
+		t.Fatalf("expected no black tripled pawns but got %v", sqs)
+	}
+}
+
+func TestPawnIslands(t *testing.T) {
+	// White: a2+b2, d4, f2+g2+h2 form three islands.  Black: one island.
+	pos := unsafeFEN("8/4pppp/8/8/3P4/8/PP3PPP/8 w - - 0 1")
+	if got := pos.PawnIslands(White); got != 3 {
+		t.Fatalf("expected 3 white pawn islands but got %d", got)
+	}
+	if got := pos.PawnIslands(Black); got != 1 {
+		t.Fatalf("expected 1 black pawn island but got %d", got)
+	}
+	if got := pos.TripledPawns(White); len(got) != 0 {
+		t.Fatalf("expected no tripled pawns but got %v", got)
+	}
+
+	// No pawns means no islands.
+	pos = unsafeFEN("4k3/8/8/8/8/8/8/4K3 w - - 0 1")
+	if got := pos.PawnIslands(White); got != 0 {
+		t.Fatalf("expected 0 pawn islands but got %d", got)
+	}
+}
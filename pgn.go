@@ -27,7 +27,37 @@ type Parser struct {
 	currentMove *Move
 	tokens      []Token
 	errors      []ParserError
-	position    int
+	// pendingComments holds comment text seen before a move (after a
+	// move number, an ellipsis, or at the start of the movetext) until
+	// the move it belongs to is parsed.
+	pendingComments string
+	position        int
+}
+
+// collectPreMoveComments consumes comment tokens appearing before a
+// move and stores their text until the move is parsed.  Commands inside
+// such comments describe the position they follow and so still attach
+// to the previous move.
+func (p *Parser) collectPreMoveComments() error {
+	for p.currentToken().Type == CommentStart {
+		comment, commandMap, err := p.parseComment()
+		if err != nil {
+			return err
+		}
+		if p.pendingComments != "" {
+			p.pendingComments += " " + comment
+		} else {
+			p.pendingComments = comment
+		}
+		if len(commandMap) > 0 {
+			if p.currentMove != nil && p.currentMove.command != nil {
+				maps.Copy(p.currentMove.command, commandMap)
+			} else if p.currentMove != nil {
+				p.currentMove.command = commandMap
+			}
+		}
+	}
+	return nil
 }
 
 // NewParser creates a new parser instance initialized with the given tokens.
@@ -100,9 +130,9 @@ func (p *Parser) Parse() (*Game, error) {
 		return nil, err
 	}
 
-	if p.game.outcome == UnknownOutcome {
-		p.game.outcome = NoOutcome
-	}
+	// Games without a result terminator keep UnknownOutcome so callers
+	// can tell "result never recorded" apart from an explicit "*"
+	// (ongoing).  Both serialize as "*".
 	return p.game, nil
 }
 
@@ -185,10 +215,16 @@ func (p *Parser) parseMoveText() error {
 			if p.currentToken().Type == DOT {
 				p.advance()
 			}
+			if err := p.collectPreMoveComments(); err != nil {
+				return err
+			}
 
 		case ELLIPSIS:
 			p.advance()
 			ply++
+			if err := p.collectPreMoveComments(); err != nil {
+				return err
+			}
 
 		case PIECE, SQUARE, FILE, KingsideCastle, QueensideCastle:
 			move, err := p.parseMove()
@@ -200,12 +236,16 @@ func (p *Parser) parseMoveText() error {
 			}
 			p.addMove(move)
 			ply++
+			if p.pendingComments != "" {
+				p.currentMove.preComments = p.pendingComments
+				p.pendingComments = ""
+			}
 
 			// Collect all NAGs and comments that follow the move
 			for {
 				tok := p.currentToken()
 				if tok.Type == NAG {
-					p.currentMove.nag = tok.Value
+					p.currentMove.nags = append(p.currentMove.nags, tok.Value)
 					p.advance()
 				} else if tok.Type == CommentStart {
 					comment, commandMap, err := p.parseComment()
@@ -230,6 +270,14 @@ func (p *Parser) parseMoveText() error {
 			}
 
 		case CommentStart:
+			// A comment before the first move belongs to the move that
+			// follows it.
+			if p.currentMove == p.game.rootMove {
+				if err := p.collectPreMoveComments(); err != nil {
+					return err
+				}
+				continue
+			}
 			comment, commandMap, err := p.parseComment()
 			if err != nil {
 				return err
@@ -296,7 +344,7 @@ func (p *Parser) parseMove() (*Move, error) {
 			if m.HasTag(QueenSideCastle) {
 				move.s1 = m.S1()
 				move.s2 = m.S2()
-				move.position = p.game.pos
+				move.position = p.game.pos.copy()
 				if m.HasTag(Check) {
 					move.AddTag(Check)
 				}
@@ -480,9 +528,9 @@ func (p *Parser) parseMove() (*Move, error) {
 		p.advance()
 	}
 
-	// Handle NAG if present
-	if p.currentToken().Type == NAG {
-		move.nag = p.currentToken().Value
+	// Handle NAGs if present
+	for p.currentToken().Type == NAG {
+		move.nags = append(move.nags, p.currentToken().Value)
 		p.advance()
 	}
 
@@ -353,8 +353,8 @@ func TestCompleteGame(t *testing.T) {
 		t.Fatalf("game move 4 is not correct, expected comment, got %s", moves[6].comments)
 	}
 
-	if moves[44].nag != "?!" {
-		t.Fatalf("game move 44 is not correct, expected nag '!?', got %s", moves[44].nag)
+	if moves[44].NAG() != "?!" {
+		t.Fatalf("game move 44 is not correct, expected nag '!?', got %s", moves[44].NAG())
 	}
 }
 
@@ -428,17 +428,52 @@ func TestParseMoveWithNAGAndComment(t *testing.T) {
 		t.Fatalf("expected at least 4 moves, got %d", len(moves))
 	}
 
-	if moves[0].nag == "" || moves[0].comments == "" {
-		t.Errorf("move 1 should have both NAG and comment, got nag: '%s', comment: '%s'", moves[0].nag, moves[0].comments)
+	if moves[0].NAG() == "" || moves[0].comments == "" {
+		t.Errorf("move 1 should have both NAG and comment, got nag: '%s', comment: '%s'", moves[0].NAG(), moves[0].comments)
 	}
-	if moves[1].nag == "" || moves[1].comments == "" {
-		t.Errorf("move 2 should have both NAG and comment, got nag: '%s', comment: '%s'", moves[1].nag, moves[1].comments)
+	if moves[1].NAG() == "" || moves[1].comments == "" {
+		t.Errorf("move 2 should have both NAG and comment, got nag: '%s', comment: '%s'", moves[1].NAG(), moves[1].comments)
 	}
-	if moves[2].nag == "" || moves[2].comments == "" {
-		t.Errorf("move 3 should have both NAG and comment, got nag: '%s', comment: '%s'", moves[2].nag, moves[2].comments)
+	if moves[2].NAG() == "" || moves[2].comments == "" {
+		t.Errorf("move 3 should have both NAG and comment, got nag: '%s', comment: '%s'", moves[2].NAG(), moves[2].comments)
 	}
-	if moves[3].nag == "" || moves[3].comments == "" {
-		t.Errorf("move 4 should have both NAG and comment, got nag: '%s', comment: '%s'", moves[3].nag, moves[3].comments)
+	if moves[3].NAG() == "" || moves[3].comments == "" {
+		t.Errorf("move 4 should have both NAG and comment, got nag: '%s', comment: '%s'", moves[3].NAG(), moves[3].comments)
+	}
+}
+
+func TestParseMoveWithMultipleNAGs(t *testing.T) {
+	pgn := `[Event "Test"]
+[Site "Internet"]
+[Date "2023.12.06"]
+[Round "1"]
+[White "Player1"]
+[Black "Player2"]
+[Result "1-0"]
+
+1. e4 $1 $15 e5 2. Nf3 1-0`
+
+	scanner := NewScanner(strings.NewReader(pgn))
+	game, err := scanner.ParseNext()
+	if err != nil {
+		t.Fatalf("fail to parse game: %v", err)
+	}
+
+	moves := game.Moves()
+	if len(moves) < 3 {
+		t.Fatalf("expected at least 3 moves, got %d", len(moves))
+	}
+
+	nags := moves[0].NAGs()
+	if len(nags) != 2 || nags[0] != "$1" || nags[1] != "$15" {
+		t.Fatalf("move 1 should have NAGs [$1 $15], got %v", nags)
+	}
+	if moves[0].NAG() != "$1 $15" {
+		t.Fatalf("move 1 NAG() should be '$1 $15', got '%s'", moves[0].NAG())
+	}
+
+	if !strings.Contains(game.String(), "1. e4 $1 $15 e5") {
+		t.Fatalf("expected both NAGs in output, got %s", game.String())
 	}
 }
 
@@ -499,3 +534,222 @@ func TestVariationMoveNumbers(t *testing.T) {
 		t.Errorf("variation reply: expected move number 3 or 4, got %d", variation[0].children[0].Ply())
 	}
 }
+
+func TestParseGluedMoveNumbers(t *testing.T) {
+	// Lichess and some exporters omit the space after the move number dot.
+	pgn := `[Event "Test"]
+[Site "Internet"]
+[Date "2023.12.06"]
+[Round "1"]
+[White "Player1"]
+[Black "Player2"]
+[Result "*"]
+
+1.e4 e5 2.Nf3 Nc6 3.Bb5 a6 *`
+
+	scanner := NewScanner(strings.NewReader(pgn))
+	game, err := scanner.ParseNext()
+	if err != nil {
+		t.Fatalf("fail to parse game: %v", err)
+	}
+	moves := game.Moves()
+	if len(moves) != 6 {
+		t.Fatalf("expected 6 moves, got %d", len(moves))
+	}
+	if !strings.Contains(game.String(), "1. e4 e5 2. Nf3 Nc6 3. Bb5 a6") {
+		t.Fatalf("glued input did not normalize, got %s", game.String())
+	}
+}
+
+func TestParseQueensideCastlePosition(t *testing.T) {
+	pgn := `[Event "Test"]
+[Site "Internet"]
+[Date "2023.12.06"]
+[Round "1"]
+[White "Player1"]
+[Black "Player2"]
+[Result "*"]
+
+1. d4 d5 2. Nc3 Nc6 3. Bf4 Bf5 4. Qd2 Qd7 5. O-O-O O-O-O 6. Nf3 h6 *`
+
+	scanner := NewScanner(strings.NewReader(pgn))
+	game, err := scanner.ParseNext()
+	if err != nil {
+		t.Fatalf("fail to parse game: %v", err)
+	}
+	moves := game.Moves()
+	if len(moves) != 12 {
+		t.Fatalf("expected 12 moves, got %d", len(moves))
+	}
+
+	// The cached position of white's queenside castle must be independent
+	// of the game position and reflect the board right after the castle.
+	castle := moves[8]
+	if !castle.HasTag(QueenSideCastle) {
+		t.Fatalf("expected queenside castle, got %s", castle.String())
+	}
+	if castle.Position() == game.Position() {
+		t.Fatal("castle move aliases the game position")
+	}
+	board := castle.Position().Board()
+	if board.Piece(C1) != WhiteKing || board.Piece(D1) != WhiteRook {
+		t.Fatalf("castle position not correct, got %s", castle.Position().String())
+	}
+	// Later moves must not leak into the cached position.
+	if board.Piece(F3) == WhiteKnight {
+		t.Fatal("castle position includes a later move")
+	}
+}
+
+func TestParseResultOngoingVsUnknown(t *testing.T) {
+	// An explicit "*" terminator marks an ongoing game.
+	scanner := NewScanner(strings.NewReader("1. e4 e5 *"))
+	game, err := scanner.ParseNext()
+	if err != nil {
+		t.Fatalf("fail to parse game: %v", err)
+	}
+	if game.Outcome() != NoOutcome {
+		t.Fatalf("expected outcome %q but got %q", NoOutcome, game.Outcome())
+	}
+	if !strings.HasSuffix(game.String(), "*") {
+		t.Fatalf("expected pgn to end with * but got %q", game.String())
+	}
+
+	// Movetext without a terminator keeps the result unknown instead of
+	// relabeling the game as ongoing.
+	scanner = NewScanner(strings.NewReader("1. e4 e5"))
+	game, err = scanner.ParseNext()
+	if err != nil {
+		t.Fatalf("fail to parse game: %v", err)
+	}
+	if game.Outcome() != UnknownOutcome {
+		t.Fatalf("expected outcome %q but got %q", UnknownOutcome, game.Outcome())
+	}
+	if !game.Outcome().Ongoing() {
+		t.Fatal("expected an unknown outcome to count as ongoing")
+	}
+	// An unknown result still serializes as "*" since PGN has no way to
+	// express a missing one.
+	if !strings.HasSuffix(game.String(), "*") {
+		t.Fatalf("expected pgn to end with * but got %q", game.String())
+	}
+
+	// A zero-move game serializes the same terminator.
+	if got := NewGame().String(); !strings.HasSuffix(got, "*") {
+		t.Fatalf("expected pgn to end with * but got %q", got)
+	}
+}
+
+func TestParseBlackToMoveFromPosition(t *testing.T) {
+	pgn := mustParsePGN("fixtures/pgns/black_to_move_frompos.pgn")
+	scanner := NewScanner(strings.NewReader(pgn))
+	g, err := scanner.ParseNext()
+	if err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+
+	moves := g.Moves()
+	if len(moves) != 3 {
+		t.Fatalf("expected 3 moves but got %d", len(moves))
+	}
+	// The first move is Black's 20th.
+	if moves[0].FullMoveNumber() != 20 {
+		t.Fatalf("expected full move number 20 but got %d", moves[0].FullMoveNumber())
+	}
+	if moves[0].position.Turn() != White {
+		t.Fatal("expected white to move after the first move")
+	}
+	if moves[1].FullMoveNumber() != 21 || moves[2].FullMoveNumber() != 21 {
+		t.Fatalf("expected move 21 next but got %d and %d",
+			moves[1].FullMoveNumber(), moves[2].FullMoveNumber())
+	}
+
+	// The ellipsis is reproduced when writing the game back out.
+	if !strings.Contains(g.String(), "20... Nxe4 21. Bxf7+ Kxf7") {
+		t.Fatalf("expected black-first movetext but got %q", g.String())
+	}
+
+	// PushMove works from a black-to-move root.
+	g2 := NewGame()
+	if err := g2.SetPosition(unsafeFEN("r1bqkb1r/pppp1ppp/2n2n2/4p3/2B1P3/5N2/PPPP1PPP/RNBQK2R b KQkq - 5 20")); err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	if err := g2.PushMove("Nxe4", nil); err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	if !strings.Contains(g2.String(), "20... Nxe4") {
+		t.Fatalf("expected pushed move rendered with ellipsis but got %q", g2.String())
+	}
+}
+
+func TestParseLeadingEllipsis(t *testing.T) {
+	pgn := `[Event "?"]
+[SetUp "1"]
+[FEN "rnbqkbnr/pppppppp/8/8/3P4/8/PPP1PPPP/RNBQKBNR b KQkq - 0 1"]
+
+1... Nf6 2. c4 e6 *`
+	scanner := NewScanner(strings.NewReader(pgn))
+	g, err := scanner.ParseNext()
+	if err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	moves := g.Moves()
+	if len(moves) != 3 {
+		t.Fatalf("expected 3 moves but got %d", len(moves))
+	}
+	// The leading ellipsis move is Black's first.
+	if moves[0].String() != "g8f6" {
+		t.Fatalf("expected g8f6 but got %s", moves[0].String())
+	}
+	if moves[0].FullMoveNumber() != 1 || moves[0].position.Turn() != White {
+		t.Fatalf("expected black's move 1 but got move %d with %s to play",
+			moves[0].FullMoveNumber(), moves[0].position.Turn())
+	}
+	if moves[1].FullMoveNumber() != 2 || moves[2].FullMoveNumber() != 2 {
+		t.Fatalf("expected move 2 next but got %d and %d",
+			moves[1].FullMoveNumber(), moves[2].FullMoveNumber())
+	}
+	// The ellipsis survives a round trip.
+	if !strings.Contains(g.String(), "1... Nf6 2. c4 e6") {
+		t.Fatalf("expected leading ellipsis in output but got %q", g.String())
+	}
+}
+
+func TestParseCommentPlacement(t *testing.T) {
+	pgn := `[Event "?"]
+
+1. {a novelty} e4 {the usual reply follows} e5 2. Nf3 {pressures e5} 2... {defends} Nc6 *
+`
+	pgnOpt, err := PGN(strings.NewReader(pgn))
+	if err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	game := NewGame(pgnOpt)
+	moves := game.Moves()
+	if c := moves[0].PreComments(); c != "a novelty" {
+		t.Fatalf("expected pre-move comment on e4 but got %q", c)
+	}
+	if c := moves[0].Comments(); c != "the usual reply follows" {
+		t.Fatalf("expected post-move comment on e4 but got %q", c)
+	}
+	if c := moves[3].PreComments(); c != "defends" {
+		t.Fatalf("expected pre-move comment on Nc6 but got %q", c)
+	}
+
+	// Both placements survive a serialization round trip.
+	out := game.String()
+	for _, want := range []string{"1. {a novelty} e4 {the usual reply follows}", "2... {defends} Nc6"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in %s", want, out)
+		}
+	}
+	pgnOpt, err = PGN(strings.NewReader(out))
+	if err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	rm := NewGame(pgnOpt).Moves()
+	if rm[0].PreComments() != "a novelty" || rm[0].Comments() != "the usual reply follows" ||
+		rm[3].PreComments() != "defends" {
+		t.Fatalf("expected comment placement to round-trip in %s", out)
+	}
+}
@@ -61,6 +61,23 @@ type MoveWithWeight struct {
 	Weight uint16
 }
 
+// OpeningBook is the opening book interface consumed by Game.InBook and
+// Game.IsBookMove.  PolyglotBook satisfies it.
+type OpeningBook interface {
+	// FindMoves returns the book entries for the given position hash.
+	FindMoves(positionHash uint64) []PolyglotEntry
+}
+
+// positionBookHash returns the polyglot zobrist hash of the position.
+// ok is false when the position cannot be hashed.
+func positionBookHash(pos *Position) (uint64, bool) {
+	hash, err := NewZobristHasher().HashPosition(pos.String())
+	if err != nil {
+		return 0, false
+	}
+	return ZobristHashToUint64(hash), true
+}
+
 func MoveToPolyglot(m Move) uint16 {
 	var encoded uint16
 	encoded |= uint16(int(m.S2().File()) & 0x7)                           // bits 0-2
@@ -71,6 +88,25 @@ func MoveToPolyglot(m Move) uint16 {
 	return encoded
 }
 
+// LegalMovesPolyglot returns the legal moves of the position encoded in
+// the polyglot book move format, in the same order as ValidMoves.
+// Castling moves use the king-takes-rook encoding (e.g. e1h1) that
+// DecodeMove expects, rather than the king destination square.
+func (pos *Position) LegalMovesPolyglot() []uint16 {
+	moves := pos.ValidMoves()
+	encoded := make([]uint16, len(moves))
+	for i := range moves {
+		m := moves[i]
+		if m.HasTag(KingSideCastle) {
+			m.s2 = NewSquare(FileH, m.s2.Rank())
+		} else if m.HasTag(QueenSideCastle) {
+			m.s2 = NewSquare(FileA, m.s2.Rank())
+		}
+		encoded[i] = MoveToPolyglot(m)
+	}
+	return encoded
+}
+
 func (pm PolyglotMove) Encode() uint16 {
 	var encoded uint16
 	encoded |= uint16(pm.ToFile & 0x7)        // bits 0-2
@@ -299,7 +335,8 @@ func LoadFromBytes(data []byte) (*PolyglotBook, error) {
 }
 
 // FindMoves looks up all moves for a given position hash.
-// Returns moves sorted by weight (highest weight first).
+// Returns moves sorted by weight (highest weight first); ties are broken
+// by the encoded move value (lowest first) so the order is deterministic.
 // Returns nil if no moves are found.
 //
 // Example:
@@ -326,13 +363,133 @@ func (book *PolyglotBook) FindMoves(positionHash uint64) []PolyglotEntry {
 		moves = append(moves, book.entries[i])
 	}
 
+	// Sort by weight descending, breaking ties by the encoded move value
+	// so the order (and thus the "best" move) is deterministic regardless
+	// of the order entries appeared in the book file.
 	sort.Slice(moves, func(i, j int) bool {
-		return moves[i].Weight > moves[j].Weight
+		if moves[i].Weight != moves[j].Weight {
+			return moves[i].Weight > moves[j].Weight
+		}
+		return moves[i].Move < moves[j].Move
 	})
 
 	return moves
 }
 
+// BuildBookFromPGN scans the PGN games from r and builds a polyglot book
+// from their opening moves.  For each game the first maxPly half-moves
+// of the main line are hashed and the move occurrences tallied; moves
+// seen fewer than minGames times are dropped.  Entry weights are the
+// occurrence counts, capped at the format's uint16 maximum.  Games that
+// fail to parse are skipped, so a few corrupt entries do not abort the
+// build.
+//
+// Example:
+//
+//	book, err := BuildBookFromPGN(file, 20, 2)
+func BuildBookFromPGN(r io.Reader, maxPly, minGames int) (*PolyglotBook, error) {
+	if maxPly < 1 {
+		return nil, errors.New("chess: book max ply must be positive")
+	}
+	if minGames < 1 {
+		minGames = 1
+	}
+	type bookMove struct {
+		key  uint64
+		move uint16
+	}
+	counts := map[bookMove]int{}
+	hasher := NewZobristHasher()
+	scanner := NewScanner(r, WithSkipErrors())
+	for scanner.HasNext() {
+		game, err := scanner.ParseNext()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		positions := game.Positions()
+		for i, m := range game.Moves() {
+			if i >= maxPly {
+				break
+			}
+			hash, err := hasher.HashPosition(positions[i].String())
+			if err != nil {
+				return nil, err
+			}
+			counts[bookMove{ZobristHashToUint64(hash), MoveToPolyglot(*m)}]++
+		}
+	}
+
+	var entries []PolyglotEntry
+	for bm, n := range counts {
+		if n < minGames {
+			continue
+		}
+		weight := n
+		if weight > 0xFFFF {
+			weight = 0xFFFF
+		}
+		entries = append(entries, PolyglotEntry{Key: bm.key, Move: bm.move, Weight: uint16(weight)})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Key != entries[j].Key {
+			return entries[i].Key < entries[j].Key
+		}
+		return entries[i].Move < entries[j].Move
+	})
+	return &PolyglotBook{entries: entries}, nil
+}
+
+// Verify checks the integrity of the book.  The structural check
+// confirms the entries are sorted by key in non-decreasing order, as the
+// binary search in FindMoves requires (the loaders already reject data
+// that is not 16-byte aligned).  When against is non-nil a deep check
+// runs as well: it is called once per distinct key and every entry whose
+// key resolves to a position must decode to a legal move in that
+// position.  Returning a nil position skips the legality check for that
+// key, so partial resolvers can verify curated subsets.  An error
+// describing the first problem found is returned, or nil if the book
+// passes.
+func (book *PolyglotBook) Verify(against func(key uint64) *Position) error {
+	for i := 1; i < len(book.entries); i++ {
+		if book.entries[i-1].Key > book.entries[i].Key {
+			return fmt.Errorf("chess: polyglot book entries out of order at index %d", i)
+		}
+	}
+	if against == nil {
+		return nil
+	}
+
+	var (
+		currentKey uint64
+		legal      map[uint16]bool
+		haveKey    bool
+	)
+	for i, entry := range book.entries {
+		if !haveKey || entry.Key != currentKey {
+			currentKey = entry.Key
+			haveKey = true
+			legal = nil
+			if pos := against(entry.Key); pos != nil {
+				legal = make(map[uint16]bool)
+				for _, m := range pos.LegalMovesPolyglot() {
+					legal[m] = true
+				}
+			}
+		}
+		if legal == nil {
+			continue
+		}
+		if !legal[entry.Move] {
+			return fmt.Errorf("chess: polyglot entry %d has illegal move %#04x for key %#016x",
+				i, entry.Move, entry.Key)
+		}
+	}
+	return nil
+}
+
 // DecodeMove converts a polyglot move encoding into a more usable format.
 // The move encoding uses bit fields as follows:
 //   - bits 0-2: to file
@@ -8,6 +8,7 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -776,3 +777,269 @@ func BenchmarkToMoveMap(b *testing.B) {
 		_ = book.ToMoveMap()
 	}
 }
+
+func TestLegalMovesPolyglot(t *testing.T) {
+	pos := unsafeFEN("r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1")
+	moves := pos.ValidMoves()
+	encoded := pos.LegalMovesPolyglot()
+	if len(encoded) != len(moves) {
+		t.Fatalf("expected %d encoded moves but got %d", len(moves), len(encoded))
+	}
+
+	kingSide := MoveToPolyglot(Move{s1: E1, s2: H1})
+	queenSide := MoveToPolyglot(Move{s1: E1, s2: A1})
+	foundKingSide, foundQueenSide := false, false
+	for _, e := range encoded {
+		switch e {
+		case kingSide:
+			foundKingSide = true
+		case queenSide:
+			foundQueenSide = true
+		}
+		if !DecodeMove(e).CastlingMove {
+			m := DecodeMove(e).ToMove()
+			if !moveIsValid(pos, &m, false) {
+				t.Fatalf("encoded move %s is not legal", m.String())
+			}
+		}
+	}
+	if !foundKingSide {
+		t.Fatal("expected king side castle in king-takes-rook form")
+	}
+	if !foundQueenSide {
+		t.Fatal("expected queen side castle in king-takes-rook form")
+	}
+}
+
+func TestPolyglotWriter(t *testing.T) {
+	var out bytes.Buffer
+	// A small buffer forces several spilled runs to exercise the merge.
+	writer := NewPolyglotWriterSize(&out, 16)
+
+	const numEntries = 1000
+	for i := 0; i < numEntries; i++ {
+		entry := PolyglotEntry{
+			// Descending, colliding keys so the output order differs from
+			// the insertion order.
+			Key:    uint64((numEntries - i) % 250),
+			Move:   uint16(i),
+			Weight: uint16(i % 7),
+		}
+		if err := writer.AddEntry(entry); err != nil {
+			t.Fatal("recieved unexpected error", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	if out.Len() != numEntries*16 {
+		t.Fatalf("expected %d bytes but got %d", numEntries*16, out.Len())
+	}
+
+	book, err := LoadFromBytes(out.Bytes())
+	if err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	if len(book.entries) != numEntries {
+		t.Fatalf("expected %d entries but got %d", numEntries, len(book.entries))
+	}
+
+	// The raw output must already be sorted by key before LoadFromBytes
+	// re-sorts it.
+	var prev uint64
+	for i := 0; i < numEntries; i++ {
+		key := binary.BigEndian.Uint64(out.Bytes()[i*16 : i*16+8])
+		if key < prev {
+			t.Fatalf("entry %d has key %d out of order after %d", i, key, prev)
+		}
+		prev = key
+	}
+
+	if moves := book.FindMoves(1); moves == nil {
+		t.Fatal("expected moves for key 1")
+	}
+}
+
+func TestFindMovesDeterministicTieBreak(t *testing.T) {
+	const key = uint64(0x1234)
+	entries := []PolyglotEntry{
+		{Key: key, Move: 300, Weight: 100},
+		{Key: key, Move: 100, Weight: 100},
+		{Key: key, Move: 200, Weight: 100},
+		{Key: key, Move: 400, Weight: 50},
+	}
+	// Load in two different orders and verify the same result.
+	for _, order := range [][]int{{0, 1, 2, 3}, {2, 3, 1, 0}} {
+		book := &PolyglotBook{}
+		for _, idx := range order {
+			book.entries = append(book.entries, entries[idx])
+		}
+		sortPolyglotEntries(book.entries)
+		moves := book.FindMoves(key)
+		if len(moves) != 4 {
+			t.Fatalf("expected 4 moves but got %d", len(moves))
+		}
+		expected := []uint16{100, 200, 300, 400}
+		for i, want := range expected {
+			if moves[i].Move != want {
+				t.Fatalf("expected move %d at index %d but got %d", want, i, moves[i].Move)
+			}
+		}
+	}
+}
+
+func TestPolyglotBookVerify(t *testing.T) {
+	// Structurally sound and structurally broken books.
+	sorted := &PolyglotBook{
+		entries: []PolyglotEntry{
+			{Key: 1, Move: 100, Weight: 10},
+			{Key: 1, Move: 101, Weight: 20},
+			{Key: 2, Move: 200, Weight: 30},
+		},
+	}
+	if err := sorted.Verify(nil); err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	unsorted := &PolyglotBook{
+		entries: []PolyglotEntry{
+			{Key: 2, Move: 200, Weight: 30},
+			{Key: 1, Move: 100, Weight: 10},
+		},
+	}
+	if err := unsorted.Verify(nil); err == nil {
+		t.Fatal("expected error for unsorted book")
+	}
+
+	// Deep check: the starting position with a legal and an illegal move.
+	pos := StartingPosition()
+	legalMove := pos.LegalMovesPolyglot()[0]
+	const startKey = uint64(0x463b96181691fc9c)
+	resolve := func(key uint64) *Position {
+		if key == startKey {
+			return pos
+		}
+		return nil
+	}
+
+	good := &PolyglotBook{
+		entries: []PolyglotEntry{
+			{Key: startKey, Move: legalMove, Weight: 1},
+			{Key: startKey + 1, Move: 0xffff, Weight: 1}, // Unresolved key is skipped
+		},
+	}
+	if err := good.Verify(resolve); err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+
+	bad := &PolyglotBook{
+		entries: []PolyglotEntry{
+			{Key: startKey, Move: 0xffff, Weight: 1},
+		},
+	}
+	if err := bad.Verify(resolve); err == nil {
+		t.Fatal("expected error for illegal book move")
+	}
+}
+
+func TestBuildBookFromPGN(t *testing.T) {
+	pgn := `[Event "A"]
+
+1. e4 e5 2. Nf3 1-0
+
+[Event "B"]
+
+1. e4 c5 2. Nf3 0-1
+
+[Event "C"]
+
+1. d4 d5 1/2-1/2
+
+`
+	book, err := BuildBookFromPGN(strings.NewReader(pgn), 2, 2)
+	if err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+
+	// The starting position keeps only 1. e4, played in two games.
+	const startingHash = uint64(0x463b96181691fc9c)
+	entries := book.FindMoves(startingHash)
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 book move for the starting position but got %d", len(entries))
+	}
+	if entries[0].Weight != 2 {
+		t.Fatalf("expected weight 2 but got %d", entries[0].Weight)
+	}
+	if got := DecodeMove(entries[0].Move).ToMove(); got.S1() != E2 || got.S2() != E4 {
+		t.Fatalf("expected e2e4 but got %s", got.String())
+	}
+
+	// Replies were each seen once and fall under the threshold.
+	if err := book.Verify(nil); err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	total := 0
+	for range book.ToMoveMap() {
+		total++
+	}
+	if total != 1 {
+		t.Fatalf("expected 1 position in the book but got %d", total)
+	}
+
+	if _, err := BuildBookFromPGN(strings.NewReader(pgn), 0, 1); err == nil {
+		t.Fatal("expected error for non-positive max ply")
+	}
+}
+
+func TestGameBookQueries(t *testing.T) {
+	pgn := `[Event "A"]
+
+1. e4 e5 1-0
+
+[Event "B"]
+
+1. e4 c5 0-1
+
+`
+	book, err := BuildBookFromPGN(strings.NewReader(pgn), 2, 1)
+	if err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+
+	g := NewGame()
+	if !g.InBook(book) {
+		t.Fatal("expected the starting position to be in book")
+	}
+	if g.IsBookMove(book) {
+		t.Fatal("expected no book move before any move is played")
+	}
+
+	if err := g.PushMove("e4", nil); err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	if !g.IsBookMove(book) {
+		t.Fatal("expected e4 to be a book move")
+	}
+	if !g.InBook(book) {
+		t.Fatal("expected the position after e4 to be in book")
+	}
+
+	if err := g.PushMove("e5", nil); err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	if !g.IsBookMove(book) {
+		t.Fatal("expected e5 to be a book move")
+	}
+	if g.InBook(book) {
+		t.Fatal("expected the position after e5 to be out of book")
+	}
+
+	if err := g.PushMove("Nf3", nil); err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	if g.IsBookMove(book) {
+		t.Fatal("expected Nf3 to be out of book")
+	}
+	if g.InBook(nil) || g.IsBookMove(nil) {
+		t.Fatal("expected a nil book to report false")
+	}
+}
@@ -0,0 +1,232 @@
+package chess
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"sort"
+)
+
+// defaultWriterBufferSize is the number of entries a PolyglotWriter
+// buffers in memory before spilling a sorted run to a temporary file.
+const defaultWriterBufferSize = 1 << 16
+
+// PolyglotWriter incrementally writes polyglot book entries to a
+// destination in sorted key order without holding the whole book in
+// memory.  Entries are buffered and spilled to sorted temporary run
+// files; Close merges the runs into the destination.  This allows books
+// larger than RAM to be produced, unlike NewPolyglotBookFromMap.
+//
+// Example usage:
+//
+//	file, err := os.Create("openings.bin")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer file.Close()
+//
+//	writer := NewPolyglotWriter(file)
+//	for _, entry := range entries {
+//	    if err := writer.Add(entry.hash, entry.move, entry.weight); err != nil {
+//	        log.Fatal(err)
+//	    }
+//	}
+//	if err := writer.Close(); err != nil {
+//	    log.Fatal(err)
+//	}
+type PolyglotWriter struct {
+	dst         io.Writer
+	buf         []PolyglotEntry
+	runs        []*os.File
+	maxBuffered int
+	closed      bool
+}
+
+// NewPolyglotWriter returns a PolyglotWriter writing to dst with the
+// default in-memory buffer size.
+func NewPolyglotWriter(dst io.Writer) *PolyglotWriter {
+	return NewPolyglotWriterSize(dst, defaultWriterBufferSize)
+}
+
+// NewPolyglotWriterSize returns a PolyglotWriter writing to dst that
+// buffers at most bufferSize entries in memory before spilling a run to
+// disk.
+func NewPolyglotWriterSize(dst io.Writer, bufferSize int) *PolyglotWriter {
+	if bufferSize < 1 {
+		bufferSize = 1
+	}
+	return &PolyglotWriter{
+		dst:         dst,
+		buf:         make([]PolyglotEntry, 0, bufferSize),
+		maxBuffered: bufferSize,
+	}
+}
+
+// Add buffers an entry for the given position hash, move, and weight.
+func (w *PolyglotWriter) Add(positionHash uint64, move Move, weight uint16) error {
+	return w.AddEntry(PolyglotEntry{
+		Key:    positionHash,
+		Move:   MoveToPolyglot(move),
+		Weight: weight,
+	})
+}
+
+// AddEntry buffers a raw polyglot entry.  Entries may be added in any
+// order; Close writes them out sorted by key.
+func (w *PolyglotWriter) AddEntry(entry PolyglotEntry) error {
+	if w.closed {
+		return errors.New("chess: polyglot writer is closed")
+	}
+	w.buf = append(w.buf, entry)
+	if len(w.buf) >= w.maxBuffered {
+		return w.spill()
+	}
+	return nil
+}
+
+// Close merges the in-memory buffer with any spilled runs and writes the
+// combined entries to the destination in sorted key order.  It removes
+// the temporary run files and must be called exactly once; subsequent
+// calls are no-ops.
+func (w *PolyglotWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	defer w.cleanup()
+
+	sortPolyglotEntries(w.buf)
+
+	cursors := make([]*runCursor, 0, len(w.runs))
+	for _, f := range w.runs {
+		c := &runCursor{file: f}
+		if err := c.advance(); err != nil {
+			return err
+		}
+		cursors = append(cursors, c)
+	}
+
+	bufIdx := 0
+	for {
+		var minEntry *PolyglotEntry
+		var minCursor *runCursor
+		if bufIdx < len(w.buf) {
+			minEntry = &w.buf[bufIdx]
+		}
+		for _, c := range cursors {
+			if !c.ok {
+				continue
+			}
+			if minEntry == nil || polyglotEntryLess(&c.entry, minEntry) {
+				minEntry = &c.entry
+				minCursor = c
+			}
+		}
+		if minEntry == nil {
+			return nil
+		}
+		if err := writePolyglotEntry(w.dst, minEntry); err != nil {
+			return err
+		}
+		if minCursor != nil {
+			if err := minCursor.advance(); err != nil {
+				return err
+			}
+		} else {
+			bufIdx++
+		}
+	}
+}
+
+// cleanup closes and removes all temporary run files.
+func (w *PolyglotWriter) cleanup() {
+	for _, f := range w.runs {
+		f.Close()
+		os.Remove(f.Name())
+	}
+	w.runs = nil
+}
+
+// spill sorts the buffered entries and writes them to a temporary run
+// file for merging in Close.
+func (w *PolyglotWriter) spill() error {
+	if len(w.buf) == 0 {
+		return nil
+	}
+	sortPolyglotEntries(w.buf)
+	run, err := os.CreateTemp("", "polyglot-run-*")
+	if err != nil {
+		return err
+	}
+	for i := range w.buf {
+		if writeErr := writePolyglotEntry(run, &w.buf[i]); writeErr != nil {
+			run.Close()
+			os.Remove(run.Name())
+			return writeErr
+		}
+	}
+	if _, seekErr := run.Seek(0, io.SeekStart); seekErr != nil {
+		run.Close()
+		os.Remove(run.Name())
+		return seekErr
+	}
+	w.runs = append(w.runs, run)
+	w.buf = w.buf[:0]
+	return nil
+}
+
+// runCursor iterates over the entries of a spilled run file.
+type runCursor struct {
+	file  *os.File
+	entry PolyglotEntry
+	ok    bool
+}
+
+// advance reads the next entry of the run, setting ok to false at EOF.
+func (c *runCursor) advance() error {
+	var buf [16]byte
+	_, err := io.ReadFull(c.file, buf[:])
+	if err == io.EOF {
+		c.ok = false
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	c.entry = PolyglotEntry{
+		Key:    binary.BigEndian.Uint64(buf[0:8]),
+		Move:   binary.BigEndian.Uint16(buf[8:10]),
+		Weight: binary.BigEndian.Uint16(buf[10:12]),
+		Learn:  binary.BigEndian.Uint32(buf[12:16]),
+	}
+	c.ok = true
+	return nil
+}
+
+// sortPolyglotEntries sorts entries by key, breaking ties by move for a
+// deterministic output.
+func sortPolyglotEntries(entries []PolyglotEntry) {
+	sort.Slice(entries, func(i, j int) bool {
+		return polyglotEntryLess(&entries[i], &entries[j])
+	})
+}
+
+// polyglotEntryLess reports whether a orders before b.
+func polyglotEntryLess(a, b *PolyglotEntry) bool {
+	if a.Key != b.Key {
+		return a.Key < b.Key
+	}
+	return a.Move < b.Move
+}
+
+// writePolyglotEntry writes a single 16-byte big-endian polyglot entry.
+func writePolyglotEntry(dst io.Writer, entry *PolyglotEntry) error {
+	var buf [16]byte
+	binary.BigEndian.PutUint64(buf[0:8], entry.Key)
+	binary.BigEndian.PutUint16(buf[8:10], entry.Move)
+	binary.BigEndian.PutUint16(buf[10:12], entry.Weight)
+	binary.BigEndian.PutUint32(buf[12:16], entry.Learn)
+	_, err := dst.Write(buf[:])
+	return err
+}
@@ -25,6 +25,9 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"hash/fnv"
+	"math/bits"
+	"strconv"
 	"strings"
 )
 
@@ -90,6 +93,67 @@ func StartingPosition() *Position {
 	return pos
 }
 
+// chess960KnightPairs lists the free-square indices of the two knights
+// for each value of the final Scharnagl digit (0-9).
+var chess960KnightPairs = [10][2]int{
+	{0, 1}, {0, 2}, {0, 3}, {0, 4}, {1, 2},
+	{1, 3}, {1, 4}, {2, 3}, {2, 4}, {3, 4},
+}
+
+// Chess960Position returns the Chess960 (Fischer Random) starting
+// position for the given Scharnagl index (0-959).  The castling rights
+// are encoded in Shredder-FEN form, naming the rook files, e.g. HAha.
+// Index 518 yields the piece placement of standard chess.  An error is
+// returned when the index is out of range.
+//
+// Example:
+//
+//	pos, err := Chess960Position(518)
+func Chess960Position(n int) (*Position, error) {
+	if n < 0 || n > 959 {
+		return nil, fmt.Errorf("chess: chess960 index %d out of range", n)
+	}
+	var rank [8]byte
+	// free returns the file of the i-th empty square from the a file.
+	free := func(i int) File {
+		for f := FileA; f <= FileH; f++ {
+			if rank[f] != 0 {
+				continue
+			}
+			if i == 0 {
+				return f
+			}
+			i--
+		}
+		return FileA
+	}
+	rank[(n%4)*2+1] = 'B'
+	n /= 4
+	rank[(n%4)*2] = 'B'
+	n /= 4
+	rank[free(n%6)] = 'Q'
+	n /= 6
+	// Place the higher knight first so the lower free-square index
+	// still refers to the same file.
+	pair := chess960KnightPairs[n]
+	rank[free(pair[1])] = 'N'
+	rank[free(pair[0])] = 'N'
+	queensideRook := free(0)
+	rank[queensideRook] = 'R'
+	rank[free(0)] = 'K'
+	kingsideRook := free(0)
+	rank[kingsideRook] = 'R'
+
+	backRank := string(rank[:])
+	rights := string([]byte{
+		'A' + byte(kingsideRook), 'A' + byte(queensideRook),
+		'a' + byte(kingsideRook), 'a' + byte(queensideRook),
+	})
+	fen := fmt.Sprintf("%s/pppppppp/8/8/8/8/PPPPPPPP/%s w %s - 0 1",
+		strings.ToLower(backRank), backRank, rights)
+	return decodeFEN(fen)
+}
+
 // Update returns a new position resulting from the given move.
 // The move isn't validated - use Game.Move() for validation.
 // This method is optimized for move generation where validation
@@ -137,6 +201,39 @@ func (pos *Position) Update(m *Move) *Position {
 	}
 }
 
+// ApplyUCIMoves applies a list of moves in UCI notation to the base
+// position and returns the resulting position.  Each move is validated
+// for legality before it is applied, mirroring the semantics of the UCI
+// "position ... moves ..." command.  This is useful for reconstructing
+// the end position of an engine-reported line.  An error identifying the
+// offending move is returned if a move cannot be decoded or is illegal.
+// The base position is not modified.
+//
+// Example:
+//
+//	pos, err := ApplyUCIMoves(StartingPosition(), []string{"e2e4", "e7e5"})
+func ApplyUCIMoves(base *Position, moves []string) (*Position, error) {
+	pos := base.copy()
+	for _, s := range moves {
+		m, err := UCINotation{}.Decode(pos, s)
+		if err != nil {
+			return nil, err
+		}
+		valid := false
+		for _, vm := range pos.ValidMoves() {
+			if vm.s1 == m.s1 && vm.s2 == m.s2 && vm.promo == m.promo {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return nil, fmt.Errorf("chess: illegal move %s in position %s", s, pos.String())
+		}
+		pos = m.position
+	}
+	return pos, nil
+}
+
 // ValidMoves returns all legal moves in the current position.
 // The moves are cached for performance.
 // TODO: Can we make this more efficient? Maybe using an iterator?
@@ -148,12 +245,254 @@ func (pos *Position) ValidMoves() []Move {
 	return append([]Move(nil), pos.validMoves...)
 }
 
+// HasLegalMove returns true if the side to move has at least one legal
+// move.  Generation short-circuits as soon as one legal move is found,
+// making this much faster than checking len(ValidMoves()) > 0 when the
+// full move list isn't needed.
+func (pos *Position) HasLegalMove() bool {
+	if pos.validMoves != nil {
+		return len(pos.validMoves) > 0
+	}
+	return len(engine{}.CalcMoves(pos, true)) > 0
+}
+
+// PseudoLegalMoves returns all pseudo-legal moves in the position: the
+// moves generated before those leaving the moving side's king in check
+// are filtered out.  It is a superset of ValidMoves and is useful for
+// move ordering and algorithms that do their own legality handling.
+// Castling moves are only generated when fully legal, as castling
+// through check is not considered pseudo-legal.  The result isn't
+// cached.
+func (pos *Position) PseudoLegalMoves() []Move {
+	moves := standardMoves(pos, false, true)
+	return append(moves, castleMoves(pos)...)
+}
+
+// PseudoMobility counts the pseudo-legal moves available to the given
+// color, regardless of whose turn it is.  Mobility of both sides is a
+// common evaluation feature, and full legality is ill-defined for the
+// side not on move, so the count is deliberately pseudo-legal (see
+// PseudoLegalMoves).  When counting for the non-moving side the en
+// passant square is ignored, since it only applies to the side to move.
+// Returns 0 for NoColor.
+func (pos *Position) PseudoMobility(c Color) int {
+	if c != White && c != Black {
+		return 0
+	}
+	if c == pos.turn {
+		return len(pos.PseudoLegalMoves())
+	}
+	cp := pos.copy()
+	cp.turn = c
+	cp.enPassantSquare = NoSquare
+	return len(cp.PseudoLegalMoves())
+}
+
+// CaptureMoves returns the legal moves that capture a piece, including
+// en passant, or promote a pawn.  Quiescence searches expand exactly
+// these moves, so the restricted list is exposed directly instead of
+// requiring callers to filter ValidMoves.
+func (pos *Position) CaptureMoves() []Move {
+	var moves []Move
+	for _, m := range pos.ValidMoves() {
+		if m.HasTag(Capture) || m.HasTag(EnPassant) || m.promo != NoPieceType {
+			moves = append(moves, m)
+		}
+	}
+	return moves
+}
+
+// IsForced returns true if the side to move has exactly one legal move.
+// Tactics trainers call such positions forced; checkmate and stalemate
+// positions, with no legal moves at all, are not.
+func (pos *Position) IsForced() bool {
+	return len(pos.ValidMoves()) == 1
+}
+
+// OnlyMove returns the single legal move when the side to move has
+// exactly one, as in a forced recapture or the lone escape from check.
+// ok is false when the position allows zero or several moves.
+func (pos *Position) OnlyMove() (*Move, bool) {
+	moves := pos.ValidMoves()
+	if len(moves) != 1 {
+		return nil, false
+	}
+	return &moves[0], true
+}
+
+// CheckEvasions returns the legal moves that get the side to move out of
+// check.  Since every legal move in a check position escapes the check,
+// this is the full legal move list when in check; when the side to move
+// is not in check all legal moves are returned.  Use
+// CategorizedCheckEvasions to group the evasions by how they address the
+// check.
+func (pos *Position) CheckEvasions() []Move {
+	return pos.ValidMoves()
+}
+
+// CheckEvasionCategories groups check evasions by how they address the
+// check: capturing the checking piece, interposing a piece between the
+// checker and the king, or moving the king.  The grouping is only
+// meaningful when the side to move is in check.
+type CheckEvasionCategories struct {
+	Captures  []Move // Moves that capture the checking piece
+	Blocks    []Move // Moves that interpose between checker and king
+	KingMoves []Move // King moves out of check
+}
+
+// CategorizedCheckEvasions returns the legal moves grouped into captures
+// of the checker, blocking moves, and king moves.  When the side to move
+// is not in check, the same grouping is applied to all legal moves.
+func (pos *Position) CategorizedCheckEvasions() CheckEvasionCategories {
+	var categories CheckEvasionCategories
+	for _, m := range pos.ValidMoves() {
+		switch {
+		case pos.board.Piece(m.s1).Type() == King:
+			categories.KingMoves = append(categories.KingMoves, m)
+		case m.HasTag(Capture) || m.HasTag(EnPassant):
+			categories.Captures = append(categories.Captures, m)
+		default:
+			categories.Blocks = append(categories.Blocks, m)
+		}
+	}
+	return categories
+}
+
+// ForcingMoveCategories groups the legal forcing moves of a position:
+// checks, captures, and promotions.  Each move appears in exactly one
+// category, with checks taking precedence over promotions and promotions
+// over captures (a capture that gives check is listed as a check).
+type ForcingMoveCategories struct {
+	Checks     []Move // Moves that give check
+	Captures   []Move // Captures and en passant captures
+	Promotions []Move // Pawn promotions
+}
+
+// ForcingMoves returns the legal moves that force matters: checks,
+// captures, and promotions, partitioned by category.  The tags computed
+// during move generation are reused, so no extra analysis is performed.
+// Quiet moves are not returned.
+func (pos *Position) ForcingMoves() ForcingMoveCategories {
+	var categories ForcingMoveCategories
+	for _, m := range pos.ValidMoves() {
+		switch {
+		case m.HasTag(Check):
+			categories.Checks = append(categories.Checks, m)
+		case m.promo != NoPieceType:
+			categories.Promotions = append(categories.Promotions, m)
+		case m.HasTag(Capture) || m.HasTag(EnPassant):
+			categories.Captures = append(categories.Captures, m)
+		}
+	}
+	return categories
+}
+
+// WouldLeaveKingInCheck reports whether playing the move would leave the
+// moving side's own king in check.  The move is applied to a copy of the
+// position without any legality checks, so arbitrary candidate moves —
+// including otherwise illegal ones — can be previewed, e.g. to warn about
+// moving a pinned piece.
+func (pos *Position) WouldLeaveKingInCheck(m *Move) bool {
+	cp := pos.copy()
+	cp.board.update(m)
+	return isInCheck(cp)
+}
+
 // Status returns the position's status as one of the outcome methods.
 // Possible returns values include Checkmate, Stalemate, and NoMethod.
 func (pos *Position) Status() Method {
 	return engine{}.Status(pos)
 }
 
+// TerminalState reports whether the position ends the game on its own
+// evidence and the method that ends it.  In addition to the checkmate
+// and stalemate detection of Status it covers the automatic draws
+// computable from a bare position: the seventy-five-move rule and
+// insufficient material.  Repetition draws require game history and are
+// only detected at the Game level.
+func (pos *Position) TerminalState() (Method, bool) {
+	switch pos.Status() {
+	case Checkmate:
+		return Checkmate, true
+	case Stalemate:
+		return Stalemate, true
+	}
+	if pos.halfMoveClock >= 150 {
+		return SeventyFiveMoveRule, true
+	}
+	if !pos.board.hasSufficientMaterial() {
+		return InsufficientMaterial, true
+	}
+	return NoMethod, false
+}
+
+// Validate performs strict sanity checks on the position, catching
+// corrupted or hand-built FEN data that the decoder accepts.  It verifies
+// that each side has exactly one king, that no pawns sit on the first or
+// eighth rank, that the side not on move is not in check, and that the
+// side to move is not checked in a way no legal game can produce: by
+// more than two pieces, or by two pawns.
+func (pos *Position) Validate() error {
+	if bits.OnesCount64(uint64(pos.board.bbWhiteKing)) != 1 ||
+		bits.OnesCount64(uint64(pos.board.bbBlackKing)) != 1 {
+		return errors.New("chess: position must have exactly one king per side")
+	}
+	if (pos.board.bbWhitePawn|pos.board.bbBlackPawn)&(bbRank1|bbRank8) != 0 {
+		return errors.New("chess: position has a pawn on the first or eighth rank")
+	}
+	cp := pos.copy()
+	cp.turn = cp.turn.Other()
+	if isInCheck(cp) {
+		return errors.New("chess: side not to move is in check")
+	}
+	checkers := checkingPieces(pos)
+	if numCheckers := bits.OnesCount64(uint64(checkers)); numCheckers > 2 {
+		return fmt.Errorf("chess: side to move is checked by %d pieces", numCheckers)
+	}
+	pawnBB := pos.board.bbWhitePawn | pos.board.bbBlackPawn
+	if bits.OnesCount64(uint64(checkers&pawnBB)) > 1 {
+		return errors.New("chess: side to move is checked by two pawns")
+	}
+	return pos.validateCastleRights()
+}
+
+// validateCastleRights checks that each castling right has a king on its
+// start square and a rook on the implied corner (standard rights) or on
+// the named file's back rank (Shredder-FEN rights).
+func (pos *Position) validateCastleRights() error {
+	for _, r := range pos.castleRights {
+		var kingSq, rookSq Square
+		var c Color
+		switch {
+		case r == '-':
+			continue
+		case r == 'K':
+			c, kingSq, rookSq = White, E1, H1
+		case r == 'Q':
+			c, kingSq, rookSq = White, E1, A1
+		case r == 'k':
+			c, kingSq, rookSq = Black, E8, H8
+		case r == 'q':
+			c, kingSq, rookSq = Black, E8, A8
+		case r >= 'A' && r <= 'H':
+			c, rookSq = White, NewSquare(File(r-'A'), Rank1)
+			kingSq = pos.board.whiteKingSq
+		case r >= 'a' && r <= 'h':
+			c, rookSq = Black, NewSquare(File(r-'a'), Rank8)
+			kingSq = pos.board.blackKingSq
+		}
+		if pos.board.Piece(kingSq) != NewPiece(King, c) ||
+			(c == White && kingSq.Rank() != Rank1) ||
+			(c == Black && kingSq.Rank() != Rank8) {
+			return fmt.Errorf("chess: castle right %c without king on its start square", r)
+		}
+		if pos.board.Piece(rookSq) != NewPiece(Rook, c) {
+			return fmt.Errorf("chess: castle right %c without rook on %s", r, rookSq)
+		}
+	}
+	return nil
+}
+
 // Board returns the position's board.
 func (pos *Position) Board() *Board {
 	return pos.board
@@ -180,12 +519,59 @@ func (pos *Position) EnPassantSquare() Square {
 	return pos.enPassantSquare
 }
 
+// EnPassantTarget returns the raw en passant target square, even when no
+// capture is possible, along with whether a legal en passant capture
+// exists in the position.  Unlike XFENString, which hides non-capturable
+// targets per the X-FEN rule, this exposes both pieces of information so
+// UIs can explain the en passant rule.  NoSquare and false are returned
+// when the position has no target.
+func (pos *Position) EnPassantTarget() (Square, bool) {
+	if pos.enPassantSquare == NoSquare {
+		return NoSquare, false
+	}
+	for _, m := range pos.ValidMoves() {
+		if m.HasTag(EnPassant) {
+			return pos.enPassantSquare, true
+		}
+	}
+	return pos.enPassantSquare, false
+}
+
 // CastleRights returns the castling rights of the position.
 func (pos *Position) CastleRights() CastleRights {
 	return pos.castleRights
 }
 
-// Ply returns the half-move number (increments every move).
+// CanCastleNow reports whether the side to move can legally castle on
+// the given side right now: the right must be retained, the squares
+// between king and rook empty, and the king neither in check nor
+// crossing or landing on an attacked square.  It answers the playable
+// question, unlike CastleRights.CanCastle which only consults the
+// rights string.
+func (pos *Position) CanCastleNow(side Side) bool {
+	tag := KingSideCastle
+	if side == QueenSide {
+		tag = QueenSideCastle
+	}
+	for _, m := range castleMoves(pos) {
+		if m.HasTag(tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// FullMoveNumber returns the full move number (increments after Black's
+// move).  It is the final field of the FEN string.
+func (pos *Position) FullMoveNumber() int {
+	return pos.moveCount
+}
+
+// Ply returns the one-based number of the half-move about to be played:
+// 1 before White's first move, 2 before Black's reply, and so on.  It is
+// therefore always one greater than Move.Ply of the move that produced
+// the position, which counts half-moves already played.  0 is returned
+// for a position without a move count, e.g. the zero value.
 func (pos *Position) Ply() int {
 	if pos == nil {
 		return 0
@@ -203,23 +589,115 @@ func (pos *Position) Ply() int {
 
 // String implements the fmt.Stringer interface and returns a
 // string with the FEN format: rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1.
+// It builds the string byte-by-byte since it is called heavily by
+// repetition detection and position comparison.
 func (pos *Position) String() string {
-	b := pos.board.String()
-	t := pos.turn.String()
-	c := pos.castleRights.String()
-	sq := "-"
+	// A full FEN is at most ~90 bytes; preallocate to avoid growth.
+	buf := make([]byte, 0, 90)
+	buf = pos.board.appendFEN(buf)
+	buf = append(buf, ' ')
+	buf = append(buf, pos.turn.String()...)
+	buf = append(buf, ' ')
+	buf = append(buf, pos.castleRights...)
+	buf = append(buf, ' ')
 	if pos.enPassantSquare != NoSquare {
-		sq = pos.enPassantSquare.String()
+		buf = append(buf, pos.enPassantSquare.String()...)
+	} else {
+		buf = append(buf, '-')
 	}
-	return fmt.Sprintf("%s %s %s %s %d %d", b, t, c, sq, pos.halfMoveClock, pos.moveCount)
+	buf = append(buf, ' ')
+	buf = strconv.AppendInt(buf, int64(pos.halfMoveClock), 10)
+	buf = append(buf, ' ')
+	buf = strconv.AppendInt(buf, int64(pos.moveCount), 10)
+	return string(buf)
+}
+
+// ASCII returns a plain-text rendering of the position as an 8x8 grid
+// using FEN piece letters (uppercase for White, lowercase for Black) and
+// '.' for empty squares.  Unlike Board.Draw() it uses only ASCII
+// characters so it aligns in any terminal or log output.  When coords is
+// true, rank numbers and file letters are included around the grid.
+//
+// Example output with coords:
+//
+//	8 r n b q k b n r
+//	7 p p p p p p p p
+//	6 . . . . . . . .
+//	5 . . . . . . . .
+//	4 . . . . . . . .
+//	3 . . . . . . . .
+//	2 P P P P P P P P
+//	1 R N B Q K B N R
+//	  a b c d e f g h
+func (pos *Position) ASCII(coords bool) string {
+	var sb strings.Builder
+	for r := 7; r >= 0; r-- {
+		if coords {
+			sb.WriteByte(Rank(r).Byte())
+		}
+		for f := range numOfSquaresInRow {
+			if f > 0 || coords {
+				sb.WriteByte(' ')
+			}
+			p := pos.board.Piece(NewSquare(File(f), Rank(r)))
+			if p == NoPiece {
+				sb.WriteByte('.')
+			} else {
+				sb.WriteByte(p.getFENChar())
+			}
+		}
+		sb.WriteByte('\n')
+	}
+	if coords {
+		sb.WriteByte(' ')
+		for f := range numOfSquaresInRow {
+			sb.WriteByte(' ')
+			sb.WriteByte(File(f).Byte())
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
 }
 
 // XFENString() is similar to String() except that it returns a string with
 // the X-FEN format
 func (pos *Position) XFENString() string {
-	b := pos.board.String()
-	t := pos.turn.String()
-	c := pos.castleRights.String()
+	return fmt.Sprintf("%s %s %s %s %d %d",
+		pos.board.String(), pos.turn.String(), pos.castleRights.String(),
+		pos.xfenEnPassant(), pos.halfMoveClock, pos.moveCount)
+}
+
+// BookKey returns the position's canonical four-field key: piece
+// placement, turn, castling rights, and the en passant square under the
+// X-FEN rule (emitted only when an en passant capture is actually
+// possible).  Positions differing only in their clocks, or in an en
+// passant target no pawn can take, share a key.  This matches the en
+// passant treatment of the polyglot hashing and is the form to use for
+// opening trees and transposition tables.
+func (pos *Position) BookKey() string {
+	return fmt.Sprintf("%s %s %s %s",
+		pos.board.String(), pos.turn.String(), pos.castleRights.String(),
+		pos.xfenEnPassant())
+}
+
+// MinimalFEN returns only the first four FEN fields: board, turn,
+// castling rights, and en passant square.  This is exactly the identity
+// samePosition compares for repetition detection, and matches the
+// position part of an EPD record.  It is not a full FEN: the half-move
+// clock and move number are omitted, so positions differing only in
+// their clocks share the same string.
+func (pos *Position) MinimalFEN() string {
+	ep := "-"
+	if pos.enPassantSquare != NoSquare {
+		ep = pos.enPassantSquare.String()
+	}
+	return fmt.Sprintf("%s %s %s %s",
+		pos.board.String(), pos.turn.String(), pos.castleRights.String(), ep)
+}
+
+// xfenEnPassant returns the en passant field under the X-FEN rule: the
+// target square when a legal en passant capture exists, otherwise "-".
+func (pos *Position) xfenEnPassant() string {
 	sq := "-"
 	if pos.enPassantSquare != NoSquare {
 		// Check if there is a pawn in a position to capture en passant
@@ -252,7 +730,103 @@ func (pos *Position) XFENString() string {
 			}
 		}
 	}
-	return fmt.Sprintf("%s %s %s %s %d %d", b, t, c, sq, pos.halfMoveClock, pos.moveCount)
+	return sq
+}
+
+// A Transform is a symmetry operation for the Position.Transform method.
+type Transform int
+
+const (
+	// VerticalFlip mirrors the position across the horizontal center line
+	// and swaps piece colors, the side to move, castling rights, and the
+	// en passant square.  The transformed position is always legal.
+	VerticalFlip Transform = iota
+	// HorizontalMirror mirrors the position across the vertical center
+	// line, swapping the king side and queen side.  It is only legal when
+	// neither side retains castling rights.
+	HorizontalMirror
+)
+
+// Transform returns a copy of the position with the given symmetry
+// operation applied.  It is the position-level counterpart to Board.Flip
+// and is intended for data augmentation.  An error is returned when the
+// transform would produce an illegal position, e.g. a HorizontalMirror of
+// a position that still has castling rights.
+func (pos *Position) Transform(t Transform) (*Position, error) {
+	switch t {
+	case VerticalFlip:
+		m := map[Square]Piece{}
+		for sq := range numOfSquaresInBoard {
+			p := pos.board.Piece(Square(sq))
+			if p == NoPiece {
+				continue
+			}
+			mv := NewSquare(Square(sq).File(), 7-Square(sq).Rank())
+			m[mv] = NewPiece(p.Type(), p.Color().Other())
+		}
+		cp := pos.copy()
+		cp.board = NewBoard(m)
+		cp.turn = pos.turn.Other()
+		cp.castleRights = flipCastleRights(pos.castleRights)
+		if pos.enPassantSquare != NoSquare {
+			cp.enPassantSquare = NewSquare(pos.enPassantSquare.File(), 7-pos.enPassantSquare.Rank())
+		}
+		return cp, nil
+	case HorizontalMirror:
+		if pos.castleRights != "-" && pos.castleRights != "" {
+			return nil, errors.New("chess: cannot mirror a position with castling rights")
+		}
+		cp := pos.copy()
+		cp.board = pos.board.Flip(LeftRight)
+		if pos.enPassantSquare != NoSquare {
+			cp.enPassantSquare = NewSquare(7-pos.enPassantSquare.File(), pos.enPassantSquare.Rank())
+		}
+		return cp, nil
+	default:
+		return nil, errors.New("chess: invalid transform")
+	}
+}
+
+// flipCastleRights swaps white's and black's castle rights by swapping
+// the case of each letter, keeping white's rights first.  The relative
+// order within each color is preserved, so Shredder-FEN file letters
+// (Chess960) survive the flip alongside the standard KQkq form.
+func flipCastleRights(rights CastleRights) CastleRights {
+	var white, black []rune
+	for _, r := range string(rights) {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			black = append(black, r+'a'-'A')
+		case r >= 'a' && r <= 'z':
+			white = append(white, r-'a'+'A')
+		}
+	}
+	flipped := string(white) + string(black)
+	if flipped == "" {
+		return "-"
+	}
+	return CastleRights(flipped)
+}
+
+// repetitionHash returns a hash of the fields that matter for repetition
+// detection: piece placement, side to move, castling rights, and the en
+// passant square.  The clock fields are deliberately excluded, matching
+// samePosition.
+func (pos *Position) repetitionHash() uint64 {
+	h := fnv.New64a()
+	var buf [8]byte
+	for _, bb := range []bitboard{
+		pos.board.bbWhiteKing, pos.board.bbWhiteQueen, pos.board.bbWhiteRook,
+		pos.board.bbWhiteBishop, pos.board.bbWhiteKnight, pos.board.bbWhitePawn,
+		pos.board.bbBlackKing, pos.board.bbBlackQueen, pos.board.bbBlackRook,
+		pos.board.bbBlackBishop, pos.board.bbBlackKnight, pos.board.bbBlackPawn,
+	} {
+		binary.BigEndian.PutUint64(buf[:], uint64(bb))
+		h.Write(buf[:])
+	}
+	h.Write([]byte{byte(pos.turn), byte(pos.enPassantSquare)})
+	h.Write([]byte(pos.castleRights))
+	return h.Sum64()
 }
 
 // Hash returns a unique hash of the position.
@@ -293,17 +867,37 @@ const (
 	bitsHasEnPassant
 )
 
-// MarshalBinary implements the encoding.BinaryMarshaler interface.
+const (
+	// positionBinaryVersion is the current version of the position binary
+	// format, stored in the leading byte.  Version 2 widened the half move
+	// clock and move count to 32 bits so any valid position round trips.
+	positionBinaryVersion uint8 = 2
+	// positionBinaryLegacySize is the size of the unversioned format:
+	// 96 board bytes, half move clock, move count (2 bytes), en passant
+	// square, and a flags byte.
+	positionBinaryLegacySize = 101
+	// positionBinarySize is the size of the current format: a version
+	// byte, 96 board bytes, half move clock (4 bytes), move count
+	// (4 bytes), en passant square, and a flags byte.
+	positionBinarySize = 107
+)
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.  The
+// encoding is a version byte followed by the board's twelve bitboards,
+// the half move clock, the move count, the en passant square, and a
+// flags byte holding the castle rights and turn.
 func (pos *Position) MarshalBinary() ([]byte, error) {
 	boardBytes, err := pos.board.MarshalBinary()
 	if err != nil {
 		return nil, err
 	}
-	buf := bytes.NewBuffer(boardBytes)
-	if err = binary.Write(buf, binary.BigEndian, uint8(pos.halfMoveClock)); err != nil {
+	buf := bytes.NewBuffer(make([]byte, 0, positionBinarySize))
+	buf.WriteByte(positionBinaryVersion)
+	buf.Write(boardBytes)
+	if err = binary.Write(buf, binary.BigEndian, uint32(pos.halfMoveClock)); err != nil {
 		return nil, err
 	}
-	if err = binary.Write(buf, binary.BigEndian, uint16(pos.moveCount)); err != nil {
+	if err = binary.Write(buf, binary.BigEndian, uint32(pos.moveCount)); err != nil {
 		return nil, err
 	}
 	if err = binary.Write(buf, binary.BigEndian, pos.enPassantSquare); err != nil {
@@ -335,10 +929,24 @@ func (pos *Position) MarshalBinary() ([]byte, error) {
 }
 
 // UnmarshalBinary implements the encoding.BinaryMarshaler interface.
+// Version 1 blobs with 8-bit half move clocks and 16-bit move counts,
+// and legacy 101-byte blobs without the leading version byte, are still
+// accepted for backward compatibility.
 func (pos *Position) UnmarshalBinary(data []byte) error {
-	const size = 101
-	if len(data) != size {
-		return errors.New("chess: position binary data should consist of 101 bytes")
+	narrow := false
+	switch {
+	case len(data) == positionBinaryLegacySize:
+		// Unversioned blob written before the version byte was added.
+		narrow = true
+	case len(data) == positionBinaryLegacySize+1 && data[0] == 1:
+		narrow = true
+		data = data[1:]
+	case len(data) == positionBinarySize && data[0] == positionBinaryVersion:
+		data = data[1:]
+	case len(data) == positionBinaryLegacySize+1 || len(data) == positionBinarySize:
+		return fmt.Errorf("chess: unsupported position binary version %d", data[0])
+	default:
+		return errors.New("chess: position binary data has invalid size")
 	}
 	board := &Board{}
 	if err := board.UnmarshalBinary(data[:96]); err != nil {
@@ -346,16 +954,29 @@ func (pos *Position) UnmarshalBinary(data []byte) error {
 	}
 	pos.board = board
 	buf := bytes.NewBuffer(data[96:])
-	halfMove := uint8(pos.halfMoveClock)
-	if err := binary.Read(buf, binary.BigEndian, &halfMove); err != nil {
-		return err
-	}
-	pos.halfMoveClock = int(halfMove)
-	moveCount := uint16(pos.moveCount)
-	if err := binary.Read(buf, binary.BigEndian, &moveCount); err != nil {
-		return err
+	if narrow {
+		var halfMove uint8
+		if err := binary.Read(buf, binary.BigEndian, &halfMove); err != nil {
+			return err
+		}
+		pos.halfMoveClock = int(halfMove)
+		var moveCount uint16
+		if err := binary.Read(buf, binary.BigEndian, &moveCount); err != nil {
+			return err
+		}
+		pos.moveCount = int(moveCount)
+	} else {
+		var halfMove uint32
+		if err := binary.Read(buf, binary.BigEndian, &halfMove); err != nil {
+			return err
+		}
+		pos.halfMoveClock = int(halfMove)
+		var moveCount uint32
+		if err := binary.Read(buf, binary.BigEndian, &moveCount); err != nil {
+			return err
+		}
+		pos.moveCount = int(moveCount)
 	}
-	pos.moveCount = int(moveCount)
 	if err := binary.Read(buf, binary.BigEndian, &pos.enPassantSquare); err != nil {
 		return err
 	}
@@ -390,6 +1011,18 @@ func (pos *Position) UnmarshalBinary(data []byte) error {
 	return nil
 }
 
+// Copy returns a deep copy of the position.  The board is copied and the
+// cached valid moves are not carried over, so the copy can be mutated and
+// analyzed independently of the original.
+//
+// Example:
+//
+//	cp := pos.Copy()
+//	cp.ChangeTurn() // pos is unaffected
+func (pos *Position) Copy() *Position {
+	return pos.copy()
+}
+
 func (pos *Position) copy() *Position {
 	return &Position{
 		board:           pos.board.copy(),
@@ -1,6 +1,8 @@
 package chess
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -22,6 +24,20 @@ func TestPositionBinary(t *testing.T) {
 			t.Fatalf("expected %s but got %s", pos.String(), cp.String())
 		}
 	}
+
+	// Clocks wider than the legacy uint8/uint16 fields round trip.
+	pos := unsafeFEN("4k3/8/8/8/8/8/8/4K3 w - - 300 70000")
+	b, err := pos.MarshalBinary()
+	if err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	cp := &Position{}
+	if err := cp.UnmarshalBinary(b); err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	if pos.String() != cp.String() {
+		t.Fatalf("expected %s but got %s", pos.String(), cp.String())
+	}
 }
 
 func TestPositionUpdate(t *testing.T) {
@@ -84,3 +100,696 @@ func TestPositionPly(t *testing.T) {
 		}
 	}
 }
+
+func TestPositionASCII(t *testing.T) {
+	expected := "r n b q k b n r\n" +
+		"p p p p p p p p\n" +
+		". . . . . . . .\n" +
+		". . . . . . . .\n" +
+		". . . . . . . .\n" +
+		". . . . . . . .\n" +
+		"P P P P P P P P\n" +
+		"R N B Q K B N R\n"
+	if s := StartingPosition().ASCII(false); s != expected {
+		t.Fatalf("expected ascii board:\n%s\nbut got:\n%s", expected, s)
+	}
+	withCoords := StartingPosition().ASCII(true)
+	if !strings.Contains(withCoords, "8 r n b q k b n r") ||
+		!strings.Contains(withCoords, "  a b c d e f g h") {
+		t.Fatalf("expected coordinates in ascii board but got:\n%s", withCoords)
+	}
+}
+
+func TestPositionHasLegalMove(t *testing.T) {
+	for _, fen := range validFENs {
+		pos := &Position{}
+		if err := pos.UnmarshalText([]byte(fen)); err != nil {
+			t.Fatal("recieved unexpected error", err)
+		}
+		expected := len(pos.ValidMoves()) > 0
+		// Use a fresh position so the cached move list isn't consulted.
+		cp := pos.copy()
+		if cp.HasLegalMove() != expected {
+			t.Fatalf("expected HasLegalMove %t for fen %s", expected, fen)
+		}
+	}
+}
+
+func TestCheckEvasions(t *testing.T) {
+	// White is in check from the e8 rook and can capture it (Rxe8),
+	// block the check (e.g. Qe2), or move the king.
+	pos := &Position{}
+	if err := pos.UnmarshalText([]byte("R3r2k/8/8/8/8/8/3Q4/4K3 w - - 0 1")); err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	evasions := pos.CheckEvasions()
+	if len(evasions) != len(pos.ValidMoves()) {
+		t.Fatalf("expected %d evasions but got %d", len(pos.ValidMoves()), len(evasions))
+	}
+	categories := pos.CategorizedCheckEvasions()
+	if len(categories.Captures) == 0 {
+		t.Fatal("expected at least one capture of the checker")
+	}
+	if len(categories.Blocks) == 0 {
+		t.Fatal("expected at least one blocking move")
+	}
+	if len(categories.KingMoves) == 0 {
+		t.Fatal("expected at least one king move")
+	}
+	total := len(categories.Captures) + len(categories.Blocks) + len(categories.KingMoves)
+	if total != len(evasions) {
+		t.Fatalf("expected categories to cover %d evasions but got %d", len(evasions), total)
+	}
+}
+
+func TestApplyUCIMoves(t *testing.T) {
+	pos, err := ApplyUCIMoves(StartingPosition(), []string{"e2e4", "c7c5", "g1f3"})
+	if err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	expected := "rnbqkbnr/pp1ppppp/8/2p5/4P3/5N2/PPPP1PPP/RNBQKB1R b KQkq - 1 2"
+	if pos.String() != expected {
+		t.Fatalf("expected fen %s but got %s", expected, pos.String())
+	}
+
+	if _, err := ApplyUCIMoves(StartingPosition(), []string{"e2e5"}); err == nil {
+		t.Fatal("expected error for illegal move")
+	}
+	if _, err := ApplyUCIMoves(StartingPosition(), []string{"bogus"}); err == nil {
+		t.Fatal("expected error for malformed move")
+	}
+}
+
+func TestPositionTransform(t *testing.T) {
+	pos := &Position{}
+	if err := pos.UnmarshalText([]byte("rnbqkbnr/pp1ppppp/8/2p5/4P3/8/PPPP1PPP/RNBQKBNR w KQkq c6 0 2")); err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	flipped, err := pos.Transform(VerticalFlip)
+	if err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	expected := "rnbqkbnr/pppp1ppp/8/4p3/2P5/8/PP1PPPPP/RNBQKBNR b KQkq c3 0 2"
+	if flipped.String() != expected {
+		t.Fatalf("expected fen %s but got %s", expected, flipped.String())
+	}
+	// Flipping twice restores the original position.
+	restored, err := flipped.Transform(VerticalFlip)
+	if err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	if restored.String() != pos.String() {
+		t.Fatalf("expected fen %s but got %s", pos.String(), restored.String())
+	}
+
+	// Shredder-FEN file-letter rights survive the flip too.
+	chess960, err := Chess960Position(0)
+	if err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	flipped960, err := chess960.Transform(VerticalFlip)
+	if err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	expected = "bbqnnrkr/pppppppp/8/8/8/8/PPPPPPPP/BBQNNRKR b HFhf - 0 1"
+	if flipped960.String() != expected {
+		t.Fatalf("expected fen %s but got %s", expected, flipped960.String())
+	}
+
+	// Mirroring is rejected while castling rights remain.
+	if _, err := pos.Transform(HorizontalMirror); err == nil {
+		t.Fatal("expected error mirroring position with castling rights")
+	}
+
+	noRights := &Position{}
+	if err := noRights.UnmarshalText([]byte("7k/8/8/8/8/8/8/R6K w - - 0 1")); err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	mirrored, err := noRights.Transform(HorizontalMirror)
+	if err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	expected = "k7/8/8/8/8/8/8/K6R w - - 0 1"
+	if mirrored.String() != expected {
+		t.Fatalf("expected fen %s but got %s", expected, mirrored.String())
+	}
+}
+
+func TestPositionBinaryVersioning(t *testing.T) {
+	pos, err := decodeFEN("rnbqkbnr/pp1ppppp/8/2p5/4P3/8/PPPP1PPP/RNBQKBNR w KQkq c6 0 2")
+	if err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	b, err := pos.MarshalBinary()
+	if err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	if len(b) != 107 || b[0] != 2 {
+		t.Fatalf("expected 107 byte blob with version 2 but got %d bytes with version %d", len(b), b[0])
+	}
+
+	// A versioned blob round trips.
+	cp := &Position{}
+	if err := cp.UnmarshalBinary(b); err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	if cp.String() != pos.String() {
+		t.Fatalf("expected %s but got %s", pos.String(), cp.String())
+	}
+
+	// A version 1 blob with the narrow uint8/uint16 clocks is still
+	// accepted.  Rebuild one from the version 2 layout.
+	v1 := append([]byte{1}, b[1:97]...)
+	v1 = append(v1, b[100])         // half move clock
+	v1 = append(v1, b[103:105]...)  // move count
+	v1 = append(v1, b[105], b[106]) // en passant square and flags
+	old := &Position{}
+	if err := old.UnmarshalBinary(v1); err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	if old.String() != pos.String() {
+		t.Fatalf("expected %s but got %s", pos.String(), old.String())
+	}
+
+	// A legacy 101 byte blob (no version byte) is still accepted.
+	legacy := &Position{}
+	if err := legacy.UnmarshalBinary(v1[1:]); err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	if legacy.String() != pos.String() {
+		t.Fatalf("expected %s but got %s", pos.String(), legacy.String())
+	}
+
+	// An unknown version is rejected.
+	bad := append([]byte(nil), b...)
+	bad[0] = 99
+	if err := (&Position{}).UnmarshalBinary(bad); err == nil {
+		t.Fatal("expected error for unknown version")
+	}
+	if err := (&Position{}).UnmarshalBinary(b[:50]); err == nil {
+		t.Fatal("expected error for truncated data")
+	}
+}
+
+func TestPositionTerminalState(t *testing.T) {
+	tests := []struct {
+		fen      string
+		method   Method
+		terminal bool
+	}{
+		// Fool's mate.
+		{"rnb1kbnr/pppp1ppp/8/4p3/6Pq/5P2/PPPPP2P/RNBQKBNR w KQkq - 1 3", Checkmate, true},
+		// Stalemate.
+		{"k7/8/1Q6/8/8/8/8/7K b - - 0 1", Stalemate, true},
+		// Seventy-five-move rule.
+		{"7k/8/8/8/8/8/8/R6K w - - 150 100", SeventyFiveMoveRule, true},
+		// King versus king.
+		{"7k/8/8/8/8/8/8/7K w - - 0 1", InsufficientMaterial, true},
+		// Ongoing game.
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", NoMethod, false},
+	}
+	for _, tt := range tests {
+		pos := &Position{}
+		if err := pos.UnmarshalText([]byte(tt.fen)); err != nil {
+			t.Fatal("recieved unexpected error", err)
+		}
+		method, terminal := pos.TerminalState()
+		if method != tt.method || terminal != tt.terminal {
+			t.Fatalf("expected %d %t for fen %s but got %d %t", tt.method, tt.terminal, tt.fen, method, terminal)
+		}
+	}
+}
+
+func TestEnPassantTarget(t *testing.T) {
+	tests := []struct {
+		fen        string
+		target     Square
+		canCapture bool
+	}{
+		// White just played b2b4 and the black c4 pawn can capture on b3.
+		{"rnbqkbnr/pp1ppppp/8/8/1Pp1PP2/8/P1PP2PP/RNBQKBNR b KQkq b3 0 3", B3, true},
+		// Target exists but no black pawn is adjacent to capture it.
+		{"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1", E3, false},
+		// No en passant target at all.
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", NoSquare, false},
+	}
+	for _, tt := range tests {
+		pos := &Position{}
+		if err := pos.UnmarshalText([]byte(tt.fen)); err != nil {
+			t.Fatal("recieved unexpected error", err)
+		}
+		target, canCapture := pos.EnPassantTarget()
+		if target != tt.target || canCapture != tt.canCapture {
+			t.Fatalf("expected %s %t for fen %s but got %s %t", tt.target, tt.canCapture, tt.fen, target, canCapture)
+		}
+	}
+}
+
+func TestWouldLeaveKingInCheck(t *testing.T) {
+	// The d2 knight is pinned to the white king by the d8 rook.
+	pos := &Position{}
+	if err := pos.UnmarshalText([]byte("3r3k/8/8/8/8/8/3N4/3K4 w - - 0 1")); err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	pinned := &Move{s1: D2, s2: B3}
+	if !pos.WouldLeaveKingInCheck(pinned) {
+		t.Fatal("expected pinned knight move to leave king in check")
+	}
+	safe := &Move{s1: D1, s2: C1}
+	if pos.WouldLeaveKingInCheck(safe) {
+		t.Fatal("expected king move to be safe")
+	}
+	// The original position is untouched by the preview.
+	if pos.board.Piece(D2) != WhiteKnight {
+		t.Fatal("expected preview to leave the position unchanged")
+	}
+}
+
+func TestForcingMoves(t *testing.T) {
+	// White can promote on e8, capture the d4 pawn, or check the b6 king
+	// with the rook.
+	pos := &Position{}
+	if err := pos.UnmarshalText([]byte("8/4P3/1k6/8/3p4/3R4/8/7K w - - 0 1")); err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	forcing := pos.ForcingMoves()
+
+	if len(forcing.Promotions) == 0 {
+		t.Fatal("expected at least one promotion")
+	}
+	for _, m := range forcing.Promotions {
+		if m.promo == NoPieceType {
+			t.Fatalf("expected promotion move but got %s", m.String())
+		}
+	}
+	foundCapture := false
+	for _, m := range forcing.Captures {
+		if m.String() == "d3d4" {
+			foundCapture = true
+		}
+		if !m.HasTag(Capture) && !m.HasTag(EnPassant) {
+			t.Fatalf("expected capture move but got %s", m.String())
+		}
+	}
+	if !foundCapture {
+		t.Fatal("expected rook capture d3d4")
+	}
+	foundCheck := false
+	for _, m := range forcing.Checks {
+		if m.String() == "d3b3" {
+			foundCheck = true
+		}
+		if !m.HasTag(Check) {
+			t.Fatalf("expected checking move but got %s", m.String())
+		}
+	}
+	if !foundCheck {
+		t.Fatal("expected rook check d3b3")
+	}
+
+	// The categories are disjoint: no move appears twice.
+	seen := map[string]bool{}
+	for _, ms := range [][]Move{forcing.Checks, forcing.Captures, forcing.Promotions} {
+		for _, m := range ms {
+			key := m.String()
+			if seen[key] {
+				t.Fatalf("expected move %s in one category only", key)
+			}
+			seen[key] = true
+		}
+	}
+
+	// A position with no forcing moves returns empty categories.
+	quiet := StartingPosition().ForcingMoves()
+	if len(quiet.Checks)+len(quiet.Captures)+len(quiet.Promotions) != 0 {
+		t.Fatal("expected no forcing moves in the starting position")
+	}
+}
+
+func TestPositionStringByteIdentical(t *testing.T) {
+	fens := []string{
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+		"rnbqkbnr/pp1ppppp/8/2p5/4P3/8/PPPP1PPP/RNBQKBNR w KQkq c6 0 2",
+		"8/4P3/1k6/8/3p4/3R4/8/7K w - - 12 34",
+		"4k3/8/8/8/8/8/8/4K2R b K - 99 120",
+	}
+	for _, fen := range fens {
+		pos := unsafeFEN(fen)
+		// The byte-level builder must reproduce the decoded FEN exactly.
+		if pos.String() != fen {
+			t.Fatalf("expected fen %s but got %s", fen, pos.String())
+		}
+		sq := "-"
+		if pos.enPassantSquare != NoSquare {
+			sq = pos.enPassantSquare.String()
+		}
+		// And match the fmt based formulation it replaced byte for byte.
+		expected := fmt.Sprintf("%s %s %s %s %d %d", pos.board.String(), pos.turn.String(),
+			pos.castleRights.String(), sq, pos.halfMoveClock, pos.moveCount)
+		if pos.String() != expected {
+			t.Fatalf("expected fen %s but got %s", expected, pos.String())
+		}
+	}
+}
+
+func BenchmarkPositionString(b *testing.B) {
+	pos := unsafeFEN("rnbq1rk1/pp2ppbp/3p1np1/8/2PNP3/2N5/PP2BPPP/R1BQK2R w KQ - 1 8")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		_ = pos.String()
+	}
+}
+
+func TestPositionValidate(t *testing.T) {
+	valid := []string{
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+		// A single pawn check is legal.
+		"4k3/3P4/8/8/8/8/8/4K3 b - - 0 1",
+		// A double check from two non-pawns is legal (discovered check).
+		"4k3/4R3/8/8/1B6/8/8/4K3 b - - 0 1",
+		// Castle rights matching the rook and king placement.
+		"4k3/8/8/8/8/8/8/4K2R w K - 0 1",
+		// Shredder-FEN rights naming the rook files.
+		"rk6/pppppppp/8/8/8/8/PPPPPPPP/RK6 w Aa - 0 1",
+	}
+	for _, fen := range valid {
+		if err := unsafeFEN(fen).Validate(); err != nil {
+			t.Fatalf("expected %s to validate but got %v", fen, err)
+		}
+	}
+
+	invalid := []string{
+		// Two pawns can never give check at once.
+		"4k3/3P1P2/8/8/8/8/8/4K3 b - - 0 1",
+		// Three checking pieces is impossible.
+		"4k3/3P1P2/8/8/8/8/8/4KR2 b - - 0 1",
+		// The side not on move may not be in check.
+		"4k3/4R3/8/8/8/8/8/4K3 w - - 0 1",
+		// Pawns cannot sit on the first or eighth rank.
+		"4k2P/8/8/8/8/8/8/4K3 w - - 0 1",
+		// Kingside castle rights without a rook on h1.
+		"4k3/8/8/8/8/8/8/4K3 w K - 0 1",
+		// Castle rights with the king off its start square.
+		"4k3/8/8/8/8/8/8/3K3R w K - 0 1",
+		// Shredder-FEN rights without a rook on the named file.
+		"rk6/pppppppp/8/8/8/8/PPPPPPPP/RK6 w H - 0 1",
+	}
+	for _, fen := range invalid {
+		if err := unsafeFEN(fen).Validate(); err == nil {
+			t.Fatalf("expected %s to fail validation", fen)
+		}
+	}
+}
+
+func TestPseudoLegalMoves(t *testing.T) {
+	// The d2 knight is pinned by the e-file rook, so its moves are
+	// pseudo-legal but not legal.
+	pos := unsafeFEN("4r2k/8/8/8/8/8/3N4/3QK3 w - - 0 1")
+	pseudo := pos.PseudoLegalMoves()
+	legal := pos.ValidMoves()
+	if len(pseudo) <= len(legal) {
+		t.Fatalf("expected more pseudo-legal moves than legal moves but got %d <= %d",
+			len(pseudo), len(legal))
+	}
+
+	// Every legal move appears in the pseudo-legal superset.
+	inPseudo := make(map[string]bool, len(pseudo))
+	for _, m := range pseudo {
+		inPseudo[m.String()] = true
+	}
+	for _, m := range legal {
+		if !inPseudo[m.String()] {
+			t.Fatalf("legal move %s missing from pseudo-legal moves", m.String())
+		}
+	}
+
+	// A pinned knight move shows up only in the pseudo-legal list.
+	inLegal := make(map[string]bool, len(legal))
+	for _, m := range legal {
+		inLegal[m.String()] = true
+	}
+	if !inPseudo["d2f3"] || inLegal["d2f3"] {
+		t.Fatal("expected d2f3 to be pseudo-legal but not legal")
+	}
+}
+
+func TestPseudoMobility(t *testing.T) {
+	// Both sides have twenty moves in the starting position.
+	pos := StartingPosition()
+	if got := pos.PseudoMobility(White); got != 20 {
+		t.Fatalf("expected 20 white moves but got %d", got)
+	}
+	if got := pos.PseudoMobility(Black); got != 20 {
+		t.Fatalf("expected 20 black moves but got %d", got)
+	}
+	if got := pos.PseudoMobility(NoColor); got != 0 {
+		t.Fatalf("expected 0 moves for NoColor but got %d", got)
+	}
+
+	// After 1.e4 White's mobility jumps while Black's is unchanged.
+	pos = unsafeFEN("rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq - 0 1")
+	if white, black := pos.PseudoMobility(White), pos.PseudoMobility(Black); white <= black {
+		t.Fatalf("expected white mobility above black but got %d <= %d", white, black)
+	}
+
+	// The count includes pseudo-legal moves of a pinned piece.
+	pos = unsafeFEN("4r2k/8/8/8/8/8/3N4/3QK3 w - - 0 1")
+	if got, want := pos.PseudoMobility(White), len(pos.PseudoLegalMoves()); got != want {
+		t.Fatalf("expected %d moves but got %d", want, got)
+	}
+	if pos.PseudoMobility(White) <= len(pos.ValidMoves()) {
+		t.Fatal("expected pseudo mobility to exceed the legal move count")
+	}
+}
+
+func TestPositionCopy(t *testing.T) {
+	pos := unsafeFEN("rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1")
+	fen := pos.String()
+	// Populate the valid move cache so the copy must not share it.
+	moves := pos.ValidMoves()
+
+	cp := pos.Copy()
+	if cp == pos {
+		t.Fatal("expected a distinct position")
+	}
+	if cp.board == pos.board {
+		t.Fatal("expected a distinct board")
+	}
+	if cp.validMoves != nil {
+		t.Fatal("expected the copy's move cache to be empty")
+	}
+	if cp.String() != fen {
+		t.Fatalf("expected fen %s but got %s", fen, cp.String())
+	}
+
+	// Mutating the copy must leave the original untouched.
+	cp.ChangeTurn()
+	cp.board.update(&Move{s1: G8, s2: F6})
+	if pos.Turn() != Black {
+		t.Fatalf("expected turn %s but got %s", Black, pos.Turn())
+	}
+	if pos.String() != fen {
+		t.Fatalf("expected fen %s but got %s", fen, pos.String())
+	}
+	if got := len(pos.ValidMoves()); got != len(moves) {
+		t.Fatalf("expected %d moves but got %d", len(moves), got)
+	}
+}
+
+func TestChess960Position(t *testing.T) {
+	tests := []struct {
+		index  int
+		fen    string
+		rights CastleRights
+	}{
+		// Index 518 is the standard chess piece placement.
+		{518, "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR", "HAha"},
+		{0, "bbqnnrkr/pppppppp/8/8/8/8/PPPPPPPP/BBQNNRKR", "HFhf"},
+		{959, "rkrnnqbb/pppppppp/8/8/8/8/PPPPPPPP/RKRNNQBB", "CAca"},
+	}
+	for _, tt := range tests {
+		pos, err := Chess960Position(tt.index)
+		if err != nil {
+			t.Fatal("recieved unexpected error", err)
+		}
+		if got := pos.board.String(); got != tt.fen {
+			t.Fatalf("expected placement %s for index %d but got %s", tt.fen, tt.index, got)
+		}
+		if pos.CastleRights() != tt.rights {
+			t.Fatalf("expected rights %s for index %d but got %s", tt.rights, tt.index, pos.CastleRights())
+		}
+		if pos.Turn() != White {
+			t.Fatalf("expected white to move for index %d", tt.index)
+		}
+	}
+
+	for _, n := range []int{-1, 960} {
+		if _, err := Chess960Position(n); err == nil {
+			t.Fatalf("expected error for index %d", n)
+		}
+	}
+}
+
+func TestPositionFullMoveNumber(t *testing.T) {
+	pos := StartingPosition()
+	if got := pos.FullMoveNumber(); got != 1 {
+		t.Fatalf("expected full move number 1 but got %d", got)
+	}
+	pos = unsafeFEN("rnbqkbnr/pp1ppppp/8/2p5/4P3/8/PPPP1PPP/RNBQKBNR w KQkq c6 0 2")
+	if got := pos.FullMoveNumber(); got != 2 {
+		t.Fatalf("expected full move number 2 but got %d", got)
+	}
+
+	// Position.Ply numbers the half-move about to be played, one ahead
+	// of Move.Ply of the move that produced the position.
+	g := NewGame()
+	for _, san := range []string{"e4", "e5", "Nf3", "Nc6"} {
+		if err := g.PushMove(san, nil); err != nil {
+			t.Fatal("recieved unexpected error", err)
+		}
+		move := g.currentMove
+		if got, want := g.CurrentPosition().Ply(), move.Ply()+1; got != want {
+			t.Fatalf("expected position ply %d after %s but got %d", want, san, got)
+		}
+	}
+	if got := g.CurrentPosition().FullMoveNumber(); got != 3 {
+		t.Fatalf("expected full move number 3 but got %d", got)
+	}
+}
+
+func TestPositionCanCastleNow(t *testing.T) {
+	// White can castle both sides; Black retains only the rights.
+	pos := unsafeFEN("r3k2r/pppppppp/8/8/8/8/PPPPPPPP/R3K2R w KQkq - 0 1")
+	if !pos.CanCastleNow(KingSide) || !pos.CanCastleNow(QueenSide) {
+		t.Fatal("expected white to castle both sides")
+	}
+
+	// A piece between king and rook blocks the castle despite the right.
+	pos = unsafeFEN("r3k2r/pppppppp/8/8/8/8/PPPPPPPP/R3KB1R w KQkq - 0 1")
+	if pos.CanCastleNow(KingSide) {
+		t.Fatal("expected blocked king side castle")
+	}
+	if !pos.CanCastleNow(QueenSide) {
+		t.Fatal("expected open queen side castle")
+	}
+
+	// The king may not castle through an attacked square.
+	pos = unsafeFEN("r3k2r/pppp1ppp/8/8/8/5q2/PPPPP1PP/R3K2R w KQkq - 0 1")
+	if pos.CanCastleNow(KingSide) {
+		t.Fatal("expected castle through check to be rejected")
+	}
+
+	// Without the right the move is unavailable even on a clear path.
+	pos = unsafeFEN("r3k2r/pppppppp/8/8/8/8/PPPPPPPP/R3K2R w kq - 0 1")
+	if pos.CanCastleNow(KingSide) || pos.CanCastleNow(QueenSide) {
+		t.Fatal("expected no castling without rights")
+	}
+}
+
+func TestPositionCaptureMoves(t *testing.T) {
+	// White can capture on e5 or d5, push pawns, or promote on a8.
+	pos := unsafeFEN("4k3/P7/8/3pp3/4P3/8/8/4K3 w - - 0 1")
+	captures := pos.CaptureMoves()
+	for _, m := range captures {
+		if !m.HasTag(Capture) && !m.HasTag(EnPassant) && m.promo == NoPieceType {
+			t.Fatalf("expected only captures and promotions but got %s", m.String())
+		}
+	}
+	// exd5 plus the four a8 promotions.
+	if len(captures) != 5 {
+		t.Fatalf("expected 5 capture moves but got %d", len(captures))
+	}
+
+	// En passant captures are included.
+	pos = unsafeFEN("4k3/8/8/3Pp3/8/8/8/4K3 w - e6 0 1")
+	found := false
+	for _, m := range pos.CaptureMoves() {
+		if m.HasTag(EnPassant) {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatal("expected an en passant capture")
+	}
+
+	// A quiet position yields no capture moves.
+	if moves := StartingPosition().CaptureMoves(); len(moves) != 0 {
+		t.Fatalf("expected no capture moves but got %d", len(moves))
+	}
+}
+
+func TestPositionBookKey(t *testing.T) {
+	// Clocks do not contribute to the key.
+	a := unsafeFEN("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1")
+	b := unsafeFEN("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 42 99")
+	if a.BookKey() != b.BookKey() {
+		t.Fatalf("expected matching keys but got %q and %q", a.BookKey(), b.BookKey())
+	}
+	if want := "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq -"; a.BookKey() != want {
+		t.Fatalf("expected key %q but got %q", want, a.BookKey())
+	}
+
+	// A non-capturable en passant square is dropped per the X-FEN rule.
+	noCapture := unsafeFEN("rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1")
+	plain := unsafeFEN("rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq - 0 1")
+	if noCapture.BookKey() != plain.BookKey() {
+		t.Fatalf("expected matching keys but got %q and %q", noCapture.BookKey(), plain.BookKey())
+	}
+
+	// A capturable en passant square stays in the key.
+	capture := unsafeFEN("rnbqkbnr/ppp1pppp/8/8/3pP3/8/PPPP1PP1/RNBQKBNR b KQkq e3 0 2")
+	if !strings.HasSuffix(capture.BookKey(), " e3") {
+		t.Fatalf("expected en passant square in key but got %q", capture.BookKey())
+	}
+}
+
+func TestPositionMinimalFEN(t *testing.T) {
+	pos := unsafeFEN("rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 42 13")
+	expected := "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3"
+	if s := pos.MinimalFEN(); s != expected {
+		t.Fatalf("expected %s but got %s", expected, s)
+	}
+
+	// Positions differing only in their clocks share a minimal FEN.
+	other := unsafeFEN("rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1")
+	if pos.MinimalFEN() != other.MinimalFEN() {
+		t.Fatal("expected clock-only differences to share a minimal FEN")
+	}
+
+	// Unlike BookKey, the en passant square is kept even when no pawn
+	// can capture, matching samePosition.
+	if !strings.HasSuffix(pos.MinimalFEN(), " e3") {
+		t.Fatalf("expected en passant square in %s", pos.MinimalFEN())
+	}
+}
+
+func TestPositionOnlyMove(t *testing.T) {
+	// Back-rank check where Kg7 is the only escape.
+	pos := unsafeFEN("R6k/7p/8/8/8/8/8/7K b - - 0 1")
+	if !pos.IsForced() {
+		t.Fatal("expected a forced position")
+	}
+	move, ok := pos.OnlyMove()
+	if !ok {
+		t.Fatal("expected the only move to be returned")
+	}
+	if move.S1() != H8 || move.S2() != G7 {
+		t.Fatalf("expected h8g7 but got %s", move)
+	}
+
+	// The starting position allows plenty of moves.
+	start := unsafeFEN("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1")
+	if start.IsForced() {
+		t.Fatal("expected the starting position not to be forced")
+	}
+	if _, ok := start.OnlyMove(); ok {
+		t.Fatal("expected no only move in the starting position")
+	}
+
+	// Checkmate has no moves at all, so it is not forced either.
+	mate := unsafeFEN("R6k/6pp/8/8/8/8/8/7K b - - 0 1")
+	if mate.IsForced() {
+		t.Fatal("expected a mated position not to be forced")
+	}
+}
@@ -24,7 +24,10 @@ package chess
 import (
 	"bufio"
 	"bytes"
+	"compress/gzip"
 	"io"
+	"os"
+	"strings"
 )
 
 // GameScanned represents a complete chess game in PGN format.
@@ -73,6 +76,7 @@ type Scanner struct {
 	lastError       error        // Store last error
 	opts            ScannerOpts
 	nextParsedGames []*Game // only valid when ExpandVariations==true
+	parseErrors     []error // only populated when SkipErrors==true
 }
 
 type ScannerOption func(*Scanner)
@@ -86,8 +90,29 @@ func WithExpandVariations() ScannerOption {
 	}
 }
 
+// WithPreserveRaw() instructs the scanner to attach the original PGN text
+// of each scanned game to the parsed Game, retrievable via Game.Raw().
+func WithPreserveRaw() ScannerOption {
+	return func(s *Scanner) {
+		s.opts.PreserveRaw = true
+	}
+}
+
+// WithSkipErrors() instructs the scanner to skip games that fail to
+// tokenize or parse rather than aborting the iteration.  ParseNext moves
+// on to the next game and the errors are collected, retrievable via
+// Scanner.Errors().  This is useful when ingesting large databases that
+// contain a few corrupt entries.
+func WithSkipErrors() ScannerOption {
+	return func(s *Scanner) {
+		s.opts.SkipErrors = true
+	}
+}
+
 type ScannerOpts struct {
 	ExpandVariations bool // default false
+	PreserveRaw      bool // default false
+	SkipErrors       bool // default false
 }
 
 // NewScanner creates a new PGN scanner that reads from the provided reader.
@@ -113,6 +138,52 @@ func NewScanner(r io.Reader, opts ...ScannerOption) *Scanner {
 	return ret
 }
 
+// NewScannerFromFile opens the PGN file at path and returns a Scanner
+// reading from it together with a close function releasing the
+// underlying file.  Files compressed with gzip — detected by a .gz
+// extension or the gzip magic bytes — are decompressed transparently.
+// Scanner options are passed through to NewScanner.
+//
+// Example:
+//
+//	scanner, closeFn, err := NewScannerFromFile("games.pgn.gz")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer closeFn()
+func NewScannerFromFile(path string, opts ...ScannerOption) (*Scanner, func() error, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	br := bufio.NewReader(file)
+	magic, err := br.Peek(2)
+	if err != nil && err != io.EOF {
+		file.Close()
+		return nil, nil, err
+	}
+
+	isGzip := len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b
+	if !isGzip && !strings.HasSuffix(path, ".gz") {
+		return NewScanner(br, opts...), file.Close, nil
+	}
+
+	gz, err := gzip.NewReader(br)
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+	closeFn := func() error {
+		gzErr := gz.Close()
+		if fileErr := file.Close(); fileErr != nil {
+			return fileErr
+		}
+		return gzErr
+	}
+	return NewScanner(gz, opts...), closeFn, nil
+}
+
 // ScanGame reads and returns the next game from the source.
 // Returns nil and io.EOF when no more games are available.
 // Returns nil and an error if reading fails.
@@ -174,6 +245,11 @@ func (s *Scanner) HasNext() bool {
 // ScanGame(), TokenizeGame(), NewParser(), and Parse() enabling
 // callers to simplify iterating over each Game within a pgn file.
 //
+// When the scanner was created with WithSkipErrors(), games that fail to
+// tokenize or parse are skipped and ParseNext continues with the next
+// game; the errors are retrievable via Errors().  io.EOF is returned
+// once no parseable games remain.
+//
 // Example:
 //
 //	for scanner.HasNext() {
@@ -187,26 +263,86 @@ func (s *Scanner) ParseNext() (*Game, error) {
 		return ret, nil
 	}
 
-	scannedGame, err := s.ScanGame()
-	if err != nil {
-		return nil, err
-	}
-	tokens, err := TokenizeGame(scannedGame)
-	if err != nil {
-		return nil, err
-	}
-	parser := NewParser(tokens)
-	game, err := parser.Parse()
-	if err != nil {
-		return nil, err
+	for {
+		scannedGame, err := s.ScanGame()
+		if err != nil {
+			return nil, err
+		}
+		tokens, err := TokenizeGame(scannedGame)
+		if err != nil {
+			if s.opts.SkipErrors {
+				s.parseErrors = append(s.parseErrors, err)
+				continue
+			}
+			return nil, err
+		}
+		parser := NewParser(tokens)
+		game, err := parser.Parse()
+		if err != nil {
+			if s.opts.SkipErrors {
+				s.parseErrors = append(s.parseErrors, err)
+				continue
+			}
+			return nil, err
+		}
+		if s.opts.PreserveRaw {
+			game.raw = scannedGame.Raw
+		}
+		if !s.opts.ExpandVariations {
+			return game, nil
+		} // else
+
+		parsedGames := game.Split()
+		s.nextParsedGames = parsedGames[1:]
+		return parsedGames[0], nil
 	}
-	if !s.opts.ExpandVariations {
-		return game, nil
-	} // else
+}
+
+// Errors returns the errors collected from games skipped by a scanner
+// created with WithSkipErrors(), in the order they were encountered.
+func (s *Scanner) Errors() []error {
+	return s.parseErrors
+}
 
-	parsedGames := game.Split()
-	s.nextParsedGames = parsedGames[1:]
-	return parsedGames[0], nil
+// FilterPGN copies the games from r for which keep returns true to w in
+// PGN form, separated by blank lines, and returns the number of games
+// written.  A nil keep keeps every game.  Games that fail to parse are
+// skipped, so a corrupt entry does not abort the batch.
+//
+// Example:
+//
+//	// Keep only decisive games.
+//	n, err := FilterPGN(in, out, func(g *Game) bool {
+//	    return g.Outcome() == WhiteWon || g.Outcome() == BlackWon
+//	})
+func FilterPGN(r io.Reader, w io.Writer, keep func(*Game) bool) (int, error) {
+	scanner := NewScanner(r, WithSkipErrors())
+	count := 0
+	for scanner.HasNext() {
+		game, err := scanner.ParseNext()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return count, err
+		}
+		if keep != nil && !keep(game) {
+			continue
+		}
+		if count > 0 {
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return count, err
+			}
+		}
+		if _, err := io.WriteString(w, game.String()); err != nil {
+			return count, err
+		}
+		if _, err := io.WriteString(w, "\n"); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
 }
 
 // Split function for bufio.Scanner to split PGN games.
@@ -1,6 +1,7 @@
 package chess
 
 import (
+	"compress/gzip"
 	"errors"
 	"io"
 	"os"
@@ -328,3 +329,174 @@ func TestScannerMultiFromPosNoExpand(t *testing.T) {
 	scanner := NewScanner(reader)
 	validateExpand(t, scanner, expectedLastLines, expectedFinalPos)
 }
+
+func TestScannerPreserveRaw(t *testing.T) {
+	pgn := mustParsePGN("fixtures/pgns/single_game.pgn")
+
+	scanner := NewScanner(strings.NewReader(pgn), WithPreserveRaw())
+	game, err := scanner.ParseNext()
+	if err != nil {
+		t.Fatalf("fail to read games from valid pgn: %s", err.Error())
+	}
+	if !strings.Contains(game.Raw(), `[Event "Example"]`) {
+		t.Fatalf("expected raw pgn to contain tags, got %q", game.Raw())
+	}
+	if !strings.Contains(game.Raw(), "1. e4") {
+		t.Fatalf("expected raw pgn to contain movetext, got %q", game.Raw())
+	}
+
+	// Without the option the raw text is not retained.
+	scanner = NewScanner(strings.NewReader(pgn))
+	game, err = scanner.ParseNext()
+	if err != nil {
+		t.Fatalf("fail to read games from valid pgn: %s", err.Error())
+	}
+	if game.Raw() != "" {
+		t.Fatalf("expected no raw pgn, got %q", game.Raw())
+	}
+}
+
+func TestNewScannerFromFile(t *testing.T) {
+	countGames := func(t *testing.T, path string) int {
+		t.Helper()
+		scanner, closeFn, err := NewScannerFromFile(path)
+		if err != nil {
+			t.Fatal("recieved unexpected error", err)
+		}
+		defer closeFn()
+		count := 0
+		for scanner.HasNext() {
+			if _, err := scanner.ParseNext(); err != nil {
+				t.Fatal("recieved unexpected error", err)
+			}
+			count++
+		}
+		return count
+	}
+
+	plain := filepath.Join("fixtures/pgns", "multi_game.pgn")
+	if got := countGames(t, plain); got != 4 {
+		t.Fatalf("expected 4 games but got %d", got)
+	}
+
+	// A gzipped copy is decompressed transparently.
+	data, err := os.ReadFile(plain)
+	if err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	gzPath := filepath.Join(t.TempDir(), "multi_game.pgn.gz")
+	out, err := os.Create(gzPath)
+	if err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	gz := gzip.NewWriter(out)
+	if _, err := gz.Write(data); err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	if err := out.Close(); err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	if got := countGames(t, gzPath); got != 4 {
+		t.Fatalf("expected 4 games from gzip but got %d", got)
+	}
+
+	if _, _, err := NewScannerFromFile(filepath.Join(t.TempDir(), "missing.pgn")); err == nil {
+		t.Fatal("expected error for missing file")
+	}
+}
+
+func TestScannerSkipErrors(t *testing.T) {
+	pgn := `[Event "Good 1"]
+
+1. e4 e5 *
+
+[Event "Bad"]
+
+1. e4 e9 *
+
+[Event "Good 2"]
+
+1. d4 d5 *
+
+`
+	scanner := NewScanner(strings.NewReader(pgn), WithSkipErrors())
+	var events []string
+	for scanner.HasNext() {
+		game, err := scanner.ParseNext()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal("recieved unexpected error", err)
+		}
+		events = append(events, game.GetTagPair("Event"))
+	}
+	if len(events) != 2 || events[0] != "Good 1" || events[1] != "Good 2" {
+		t.Fatalf("expected the two good games but got %v", events)
+	}
+	if errs := scanner.Errors(); len(errs) != 1 {
+		t.Fatalf("expected 1 collected error but got %d", len(errs))
+	}
+
+	// Without the option the malformed game aborts the iteration.
+	scanner = NewScanner(strings.NewReader(pgn))
+	if _, err := scanner.ParseNext(); err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	if _, err := scanner.ParseNext(); err == nil {
+		t.Fatal("expected error for malformed game")
+	}
+}
+
+func TestFilterPGN(t *testing.T) {
+	pgn := mustParsePGN("fixtures/pgns/0006.pgn")
+
+	// Count the White wins going in.
+	scanner := NewScanner(strings.NewReader(pgn))
+	whiteWins := 0
+	total := 0
+	for scanner.HasNext() {
+		game, err := scanner.ParseNext()
+		if err != nil {
+			t.Fatal("recieved unexpected error", err)
+		}
+		total++
+		if game.Outcome() == WhiteWon {
+			whiteWins++
+		}
+	}
+	if whiteWins == 0 || whiteWins == total {
+		t.Fatalf("expected a mix of outcomes but got %d of %d white wins", whiteWins, total)
+	}
+
+	var out strings.Builder
+	n, err := FilterPGN(strings.NewReader(pgn), &out, func(g *Game) bool {
+		return g.Outcome() == WhiteWon
+	})
+	if err != nil {
+		t.Fatal("recieved unexpected error", err)
+	}
+	if n != whiteWins {
+		t.Fatalf("expected %d games written but got %d", whiteWins, n)
+	}
+
+	// The written output re-scans to the same games.
+	scanner = NewScanner(strings.NewReader(out.String()))
+	reread := 0
+	for scanner.HasNext() {
+		game, err := scanner.ParseNext()
+		if err != nil {
+			t.Fatal("recieved unexpected error", err)
+		}
+		if game.Outcome() != WhiteWon {
+			t.Fatalf("expected only white wins but got %s", game.Outcome())
+		}
+		reread++
+	}
+	if reread != whiteWins {
+		t.Fatalf("expected to re-scan %d games but got %d", whiteWins, reread)
+	}
+}
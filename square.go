@@ -31,6 +31,17 @@ func NewSquare(f File, r Rank) Square {
 	return Square(int8(r)*numOfSquaresInRow + int8(f))
 }
 
+// SquareFromString returns the square named by an algebraic coordinate
+// such as "e4".  The second return value is false if the string is not a
+// valid coordinate.
+func SquareFromString(s string) (Square, bool) {
+	sq, ok := strToSquareMap[s]
+	if !ok {
+		return NoSquare, false
+	}
+	return sq, true
+}
+
 func (sq Square) color() Color {
 	if ((sq / 8) % 2) == (sq % 2) { //nolint:mnd // this is a formula to determine the color of a square
 		return Black
@@ -27,3 +27,19 @@ func TestNewSquare(t *testing.T) {
 		}
 	}
 }
+
+func TestSquareFromString(t *testing.T) {
+	sq, ok := SquareFromString("e4")
+	if !ok || sq != E4 {
+		t.Fatalf("expected E4 but got %s %t", sq, ok)
+	}
+	sq, ok = SquareFromString("a1")
+	if !ok || sq != A1 {
+		t.Fatalf("expected A1 but got %s %t", sq, ok)
+	}
+	for _, s := range []string{"z9", "e", "e44", "E4", ""} {
+		if sq, ok := SquareFromString(s); ok || sq != NoSquare {
+			t.Fatalf("expected NoSquare false for %q but got %s %t", s, sq, ok)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package uci
+
+import (
+	"time"
+
+	"github.com/corentings/chess/v2"
+)
+
+// MoveClassification categorizes a played move relative to the engine's
+// preferred move based on the centipawn loss it incurred.
+type MoveClassification int
+
+const (
+	// BestMove indicates the played move matched the engine's best move.
+	BestMove MoveClassification = iota
+	// GoodMove indicates the played move lost fewer than 50 centipawns.
+	GoodMove
+	// Inaccuracy indicates the played move lost at least 50 centipawns.
+	Inaccuracy
+	// Mistake indicates the played move lost at least 100 centipawns.
+	Mistake
+	// Blunder indicates the played move lost at least 300 centipawns.
+	Blunder
+)
+
+// String implements the fmt.Stringer interface.
+func (mc MoveClassification) String() string {
+	switch mc {
+	case BestMove:
+		return "Best"
+	case GoodMove:
+		return "Good"
+	case Inaccuracy:
+		return "Inaccuracy"
+	case Mistake:
+		return "Mistake"
+	case Blunder:
+		return "Blunder"
+	}
+	return "Unknown"
+}
+
+// Centipawn-loss thresholds for classifying a move.
+const (
+	inaccuracyThreshold = 50
+	mistakeThreshold    = 100
+	blunderThreshold    = 300
+)
+
+// mateScore is the centipawn value used to compare mate scores against
+// centipawn scores when computing centipawn loss.
+const mateScore = 10000
+
+// AnalyzeOpts configures AnalyzeGame.  Depth and MoveTime are forwarded
+// to the engine's "go" command for each searched position; at least one
+// should be set.
+type AnalyzeOpts struct {
+	// Depth is the search depth in plies for each position.
+	// Defaults to 12 when neither Depth nor MoveTime is set.
+	Depth int
+	// MoveTime is an optional fixed search time per position.
+	MoveTime time.Duration
+}
+
+// defaultAnalyzeDepth is used when AnalyzeOpts specifies neither a depth
+// nor a move time.
+const defaultAnalyzeDepth = 12
+
+// MoveReport is the engine's assessment of a single played move.  All
+// evaluations are from the perspective of the player who made the move.
+type MoveReport struct {
+	San            string             // Move in standard algebraic notation
+	UCI            string             // Move in UCI notation
+	BestMove       string             // Engine's preferred move in UCI notation
+	EvalBefore     Score              // Evaluation before the move was played
+	EvalAfter      Score              // Evaluation after the move was played
+	MoveNumber     int                // Full move number
+	CentipawnLoss  int                // Evaluation drop caused by the move
+	Color          chess.Color        // Color of the player who moved
+	Classification MoveClassification // Classification derived from CentipawnLoss
+}
+
+// GameReport is an immutable per-move analysis of a game produced by
+// AnalyzeGame.  It is suitable for JSON serialization and does not
+// reference or mutate the analyzed game's move tree.
+type GameReport struct {
+	Moves []MoveReport
+}
+
+// AnalyzeGame evaluates every main line position of the game with the
+// engine and returns a report containing, for each played move, the
+// engine's best move, the evaluation before and after the move, and a
+// classification of the move.  The engine must already be initialized
+// (CmdUCI, CmdIsReady).  The game itself is not modified.
+//
+// Example:
+//
+//	eng, _ := uci.New("stockfish")
+//	defer eng.Close()
+//	eng.Run(uci.CmdUCI, uci.CmdIsReady, uci.CmdUCINewGame)
+//	report, err := uci.AnalyzeGame(eng, game, &uci.AnalyzeOpts{Depth: 12})
+func AnalyzeGame(eng *Engine, g *chess.Game, opts *AnalyzeOpts) (*GameReport, error) {
+	if opts == nil {
+		opts = &AnalyzeOpts{}
+	}
+	cmdGo := CmdGo{Depth: opts.Depth, MoveTime: opts.MoveTime}
+	if cmdGo.Depth == 0 && cmdGo.MoveTime == 0 {
+		cmdGo.Depth = defaultAnalyzeDepth
+	}
+
+	positions := g.Positions()
+	moves := g.Moves()
+
+	// Evaluate each position once; scores are from the perspective of the
+	// side to move in that position.
+	scores := make([]Score, len(positions))
+	bestMoves := make([]string, len(positions))
+	for i, pos := range positions {
+		if status := pos.Status(); status != chess.NoMethod {
+			if status == chess.Checkmate {
+				scores[i] = Score{Mate: 0, CP: -mateScore}
+			}
+			continue
+		}
+		if err := eng.Run(CmdPosition{Position: pos}, cmdGo); err != nil {
+			return nil, err
+		}
+		results := eng.SearchResults()
+		scores[i] = results.Info.Score
+		if results.BestMove != nil {
+			bestMoves[i] = chess.UCINotation{}.Encode(pos, results.BestMove)
+		}
+	}
+
+	report := &GameReport{Moves: make([]MoveReport, 0, len(moves))}
+	for i, move := range moves {
+		if i+1 >= len(positions) {
+			break
+		}
+		posBefore := positions[i]
+		evalBefore := scores[i]
+		// The next position is evaluated from the opponent's perspective.
+		evalAfter := negateScore(scores[i+1])
+
+		uciStr := chess.UCINotation{}.Encode(posBefore, move)
+		cpLoss := scoreCentipawns(evalBefore) - scoreCentipawns(evalAfter)
+		if cpLoss < 0 {
+			cpLoss = 0
+		}
+
+		classification := classifyMove(cpLoss)
+		if uciStr == bestMoves[i] {
+			classification = BestMove
+		}
+
+		report.Moves = append(report.Moves, MoveReport{
+			MoveNumber:     move.FullMoveNumber(),
+			Color:          posBefore.Turn(),
+			San:            chess.AlgebraicNotation{}.Encode(posBefore, move),
+			UCI:            uciStr,
+			BestMove:       bestMoves[i],
+			EvalBefore:     evalBefore,
+			EvalAfter:      evalAfter,
+			CentipawnLoss:  cpLoss,
+			Classification: classification,
+		})
+	}
+
+	return report, nil
+}
+
+// classifyMove maps a centipawn loss to a MoveClassification.
+func classifyMove(cpLoss int) MoveClassification {
+	switch {
+	case cpLoss >= blunderThreshold:
+		return Blunder
+	case cpLoss >= mistakeThreshold:
+		return Mistake
+	case cpLoss >= inaccuracyThreshold:
+		return Inaccuracy
+	}
+	return GoodMove
+}
+
+// negateScore flips a score to the opposite side's perspective.
+func negateScore(s Score) Score {
+	s.CP = -s.CP
+	s.Mate = -s.Mate
+	s.Win, s.Loss = s.Loss, s.Win
+	s.LowerBound, s.UpperBound = s.UpperBound, s.LowerBound
+	return s
+}
+
+// scoreCentipawns collapses a score to a single centipawn value so that
+// mate scores can be compared against centipawn scores.
+func scoreCentipawns(s Score) int {
+	if s.Mate > 0 {
+		return mateScore - s.Mate
+	}
+	if s.Mate < 0 {
+		return -mateScore - s.Mate
+	}
+	return s.CP
+}
@@ -0,0 +1,60 @@
+//go:build engine
+
+package uci_test
+
+import (
+	"testing"
+
+	"github.com/corentings/chess/v2"
+	"github.com/corentings/chess/v2/uci"
+)
+
+func Test_AnalyzeGame(t *testing.T) {
+	for _, name := range engines {
+		t.Run("AnalyzeGame_"+name, func(t *testing.T) {
+			if !isEngineAvailable(name) {
+				t.Skipf("engine %s not available", name)
+			}
+
+			eng, err := uci.New(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer eng.Close()
+			if err := eng.Run(uci.CmdUCI, uci.CmdIsReady, uci.CmdUCINewGame); err != nil {
+				t.Fatal(err)
+			}
+
+			// Scholar's mate: 3... Nf6 is a blunder allowing 4. Qxf7#.
+			game := chess.NewGame()
+			for _, san := range []string{"e4", "e5", "Qh5", "Nc6", "Bc4", "Nf6", "Qxf7#"} {
+				if err := game.PushMove(san, nil); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			report, err := uci.AnalyzeGame(eng, game, &uci.AnalyzeOpts{Depth: 10})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(report.Moves) != 7 {
+				t.Fatalf("expected 7 move reports, got %d", len(report.Moves))
+			}
+
+			// 3... Nf6 allows mate in one and must be classified as a blunder.
+			nf6 := report.Moves[5]
+			if nf6.San != "Nf6" {
+				t.Fatalf("expected report for Nf6, got %s", nf6.San)
+			}
+			if nf6.Classification != uci.Blunder {
+				t.Errorf("expected Nf6 to be a blunder, got %v", nf6.Classification)
+			}
+
+			// The mating move should match the engine's best move.
+			mate := report.Moves[6]
+			if mate.Classification != uci.BestMove {
+				t.Errorf("expected Qxf7# to be the best move, got %v", mate.Classification)
+			}
+		})
+	}
+}